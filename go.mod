@@ -1,6 +1,6 @@
 module github.com/artmoskvin/gomcp
 
-go 1.22.6
+go 1.23
 
 require (
 	github.com/atombender/go-jsonschema v0.17.0 // indirect
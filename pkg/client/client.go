@@ -0,0 +1,608 @@
+// Package client implements an MCP client on top of the protocol types in
+// pkg/types. A Client runs the initialize handshake, correlates responses
+// with requests and exposes typed methods for the common server features.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// ErrClosed is returned for calls made after the client was closed
+var ErrClosed = errors.New("client closed")
+
+// Option configures Client
+type Option func(*Client) error
+
+// Client is a transport-agnostic MCP client. A background goroutine reads
+// from the transport, delivering responses to waiting calls, notifications to
+// the notification handler and answering server requests. Call Initialize
+// before any other method. It is safe for concurrent use.
+type Client struct {
+	transport types.Transport
+	emitter   *types.Emitter
+	info      types.Implementation
+	ids       *types.IDGenerator
+	session   *types.ClientSession
+
+	initOpts       []types.InitializeRequestOption
+	fallback       int // extra initialize attempts with older protocol versions
+	onNotification func(method string, params json.RawMessage)
+	sampling       types.SamplingHandler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	calls *types.Correlator // closed once the read loop stops
+
+	mu         sync.Mutex
+	assemblers map[types.ProgressToken]*types.ResourceAssembler
+	nextToken  types.ProgressToken
+}
+
+// New creates a client identifying itself as info and starts reading from
+// the transport
+func New(transport types.Transport, info types.Implementation, opts ...Option) (*Client, error) {
+	if transport == nil {
+		return nil, fmt.Errorf("transport cannot be nil")
+	}
+
+	emitter, err := types.NewEmitter(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		transport:  transport,
+		emitter:    emitter,
+		info:       info,
+		calls:      types.NewCorrelator(),
+		assemblers: make(map[types.ProgressToken]*types.ResourceAssembler),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("applying client option: %w", err)
+		}
+	}
+
+	if c.ids == nil {
+		c.ids, err = types.NewIDGenerator()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.session, err = types.NewClientSession(func(n *types.InitializedNotification) error {
+		return c.emitter.Emit(n)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	go c.readLoop()
+
+	return c, nil
+}
+
+// Client options
+
+// WithInitializeOptions configures the initialize request, e.g. with
+// types.WithClientCapabilities
+func WithInitializeOptions(opts ...types.InitializeRequestOption) Option {
+	return func(c *Client) error {
+		c.initOpts = append(c.initOpts, opts...)
+		return nil
+	}
+}
+
+// WithProtocolVersionFallback lets Initialize retry up to attempts times when
+// the server rejects the requested protocol version with an error. Each retry
+// asks for the next older version in types.SupportedProtocolVersions. A server
+// that answers with a version this package cannot speak has already
+// initialized the session, so that is an error without retrying.
+func WithProtocolVersionFallback(attempts int) Option {
+	return func(c *Client) error {
+		if attempts <= 0 {
+			return fmt.Errorf("fallback attempts must be positive")
+		}
+		c.fallback = attempts
+		return nil
+	}
+}
+
+// WithNotificationHandler receives every notification from the server. It runs
+// on the read goroutine, so it must not block.
+func WithNotificationHandler(handler func(method string, params json.RawMessage)) Option {
+	return func(c *Client) error {
+		if handler == nil {
+			return fmt.Errorf("notification handler cannot be nil")
+		}
+		c.onNotification = handler
+		return nil
+	}
+}
+
+// WithSamplingHandler serves sampling/createMessage requests from the server.
+// Advertise the capability with types.WithClientSampling as well.
+func WithSamplingHandler(handler types.SamplingHandler) Option {
+	return func(c *Client) error {
+		if handler == nil {
+			return fmt.Errorf("sampling handler cannot be nil")
+		}
+		c.sampling = handler
+		return nil
+	}
+}
+
+// WithIDGenerator draws request IDs from gen instead of a private generator
+func WithIDGenerator(gen *types.IDGenerator) Option {
+	return func(c *Client) error {
+		if gen == nil {
+			return fmt.Errorf("id generator cannot be nil")
+		}
+		c.ids = gen
+		return nil
+	}
+}
+
+// Initialize runs the handshake: it sends initialize, checks the negotiated
+// protocol version and sends notifications/initialized. A failed handshake
+// may be retried.
+func (c *Client) Initialize(ctx context.Context) (*types.InitializeResult, error) {
+	if err := c.session.BeginInitialize(); err != nil {
+		return nil, err
+	}
+
+	result, err := c.negotiate(ctx)
+	if err != nil {
+		c.session.AbortInitialize()
+		return nil, err
+	}
+
+	if err := c.session.CompleteInitialize(result); err != nil {
+		c.session.AbortInitialize()
+		return nil, err
+	}
+	return result, nil
+}
+
+// negotiate sends initialize until the server agrees on a supported protocol
+// version, falling back to older versions if allowed
+func (c *Client) negotiate(ctx context.Context) (*types.InitializeResult, error) {
+	req, err := types.NewInitializeRequest(c.info, c.initOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		var result types.InitializeResult
+		err := c.send(ctx, types.MethodInitialize, req.Params, &result)
+		if err == nil {
+			if !supportedVersion(result.ProtocolVersion) {
+				// the session is initialized with this version, so asking
+				// again would be a second initialize
+				return nil, fmt.Errorf("server negotiated unsupported protocol version %q", result.ProtocolVersion)
+			}
+			return &result, nil
+		}
+
+		if !types.IsProtocolVersionError(err) || attempt >= c.fallback {
+			return nil, err
+		}
+		next, ok := types.OlderProtocolVersion(req.Params.ProtocolVersion)
+		if !ok {
+			return nil, err
+		}
+		req.Params.ProtocolVersion = next
+	}
+}
+
+// ListTools returns every tool the server offers, following pagination cursors
+func (c *Client) ListTools(ctx context.Context) (*types.ListToolsResult, error) {
+	all := &types.ListToolsResult{Tools: []types.Tool{}}
+	err := c.paginate(ctx, func(cursor *string) (*string, error) {
+		var page types.ListToolsResult
+		if err := c.call(ctx, types.MethodListTools, types.PaginatedParams{Cursor: cursor}, &page); err != nil {
+			return nil, err
+		}
+		all.Tools = append(all.Tools, page.Tools...)
+		return page.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// CallTool invokes a tool. A tool that ran but failed returns a result with
+// IsError set rather than an error.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*types.CallToolResult, error) {
+	req, err := types.NewCallToolRequest(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.CallToolResult
+	if err := c.call(ctx, types.MethodCallTool, req.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReadResource reads a resource, following pagination cursors until every
+// content block has been received. Pass types.WithResourceContentRange to
+// read only part of a text resource.
+func (c *Client) ReadResource(ctx context.Context, uri string, opts ...types.ReadResourceRequestOption) (*types.ReadResourceResult, error) {
+	req, err := types.NewReadResourceRequest(uri, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	all := &types.ReadResourceResult{Contents: []types.ResourceContent{}}
+	err = c.paginate(ctx, func(cursor *string) (*string, error) {
+		var page types.ReadResourceResult
+		params := *req
+		params.Cursor = cursor
+		if err := c.call(ctx, types.MethodReadResource, params, &page); err != nil {
+			return nil, err
+		}
+		all.Contents = append(all.Contents, page.Contents...)
+		return page.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ReadResourceStream reads a resource, writing a streamed blob to w as its
+// chunks arrive rather than holding it in memory. The returned contents keep
+// the stream reference and checksum in place of the blob. Servers that do not
+// stream the resource answer as usual, and nothing is written to w.
+func (c *Client) ReadResourceStream(ctx context.Context, uri string, w io.Writer) (*types.ReadResourceResult, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("resource URI cannot be empty")
+	}
+
+	c.mu.Lock()
+	c.nextToken++
+	token := c.nextToken
+	c.mu.Unlock()
+
+	assembler, err := types.NewResourceAssembler(token, w)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.assemblers[token] = assembler
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.assemblers, token)
+		c.mu.Unlock()
+	}()
+
+	var result types.ReadResourceResult
+	params := types.ReadResourceRequest{
+		URI:  uri,
+		Meta: map[string]interface{}{types.MetaKeyProgressToken: token},
+	}
+	if err := c.call(ctx, types.MethodReadResource, params, &result); err != nil {
+		return nil, err
+	}
+
+	for _, content := range result.Contents {
+		if content.Stream != nil {
+			if err := assembler.Finish(content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &result, nil
+}
+
+// GetPrompt renders a prompt with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*types.GetPromptResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("prompt name cannot be empty")
+	}
+
+	var result types.GetPromptResult
+	params := types.GetPromptRequest{Name: name, Arguments: args}
+	if err := c.call(ctx, types.MethodGetPrompt, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Complete asks for completions of a prompt argument or resource template variable
+func (c *Client) Complete(ctx context.Context, ref types.Reference, argName, value string) (*types.CompleteResult, error) {
+	req, err := types.NewCompleteRequest(ref, argName, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.CompleteResult
+	if err := c.call(ctx, types.MethodComplete, req.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close stops the client. Pending and later calls fail with ErrClosed. The
+// transport is not closed; a read blocked in it ends when it is.
+func (c *Client) Close() error {
+	c.cancel()
+	c.fail(ErrClosed)
+	return nil
+}
+
+// paginate calls fetch with each cursor it returns until there are no more
+// pages. A server repeating a cursor would loop forever, so that is an error.
+func (c *Client) paginate(ctx context.Context, fetch func(cursor *string) (*string, error)) error {
+	var cursor *string
+	seen := make(map[string]bool)
+	for {
+		next, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		if seen[*next] {
+			return fmt.Errorf("server repeated cursor %q", *next)
+		}
+		seen[*next] = true
+		cursor = next
+	}
+}
+
+// call sends a request once the session allows it
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	if err := c.session.CheckRequest(method); err != nil {
+		return err
+	}
+	return c.send(ctx, method, params, result)
+}
+
+// send writes a request and waits for its response. If ctx ends first the
+// server is told to stop with a cancelled notification.
+func (c *Client) send(ctx context.Context, method string, params, result interface{}) error {
+	id := c.ids.Next()
+	ch, err := c.calls.Register(id)
+	if err != nil {
+		return err
+	}
+	defer c.calls.Forget(id)
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshaling %s params: %w", method, err)
+	}
+	msg, err := json.Marshal(types.JSONRPCRequest{
+		JSONRPC: types.JSONRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  rawParams,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling %s request: %w", method, err)
+	}
+	if err := c.transport.Write(ctx, msg); err != nil {
+		return fmt.Errorf("sending %s request: %w", method, err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return c.calls.Err()
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("decoding %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		// best effort: the server may already have finished
+		_ = c.emitter.Emit(types.NewCancelledNotification(id, ctx.Err().Error()))
+		return fmt.Errorf("waiting for %s response: %w", method, ctx.Err())
+	}
+}
+
+func (c *Client) readLoop() {
+	for {
+		data, err := c.transport.Read(c.ctx)
+		if err != nil {
+			c.fail(fmt.Errorf("reading from transport: %w", err))
+			return
+		}
+		c.handleMessage(data)
+	}
+}
+
+// handleMessage routes an incoming message by its shape. Malformed messages are dropped.
+func (c *Client) handleMessage(data json.RawMessage) {
+	var probe struct {
+		ID     *types.RequestID `json:"id"`
+		Method string           `json:"method"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return
+	}
+
+	switch {
+	case probe.Method == "" && probe.ID != nil:
+		var resp types.JSONRPCResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return
+		}
+		c.calls.Deliver(&resp)
+	case probe.Method != "" && probe.ID == nil:
+		var n types.JSONRPCNotification
+		if err := json.Unmarshal(data, &n); err != nil {
+			return
+		}
+		switch n.Method {
+		case types.MethodCancelled:
+			c.cancelPending(n.Params)
+		case types.MethodProgress:
+			c.assembleChunk(n.Params)
+		}
+		if c.onNotification != nil {
+			c.onNotification(n.Method, n.Params)
+		}
+	case probe.Method != "":
+		var req types.JSONRPCRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return
+		}
+		go c.handleRequest(req)
+	}
+}
+
+// cancelPending fails a call the server cancelled, e.g. while shutting down,
+// as no response will follow
+func (c *Client) cancelPending(rawParams json.RawMessage) {
+	var params types.CancelledParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return
+	}
+
+	message := "Request cancelled by server"
+	if params.Reason != nil {
+		message += ": " + *params.Reason
+	}
+	c.calls.Deliver(&types.JSONRPCResponse{
+		JSONRPC: types.JSONRPCVersion,
+		ID:      params.RequestID,
+		Error:   &types.ErrorInfo{Code: types.ErrInternal, Message: message},
+	})
+}
+
+// assembleChunk feeds a progress notification to the assembler of the
+// streamed read it belongs to, if any. Errors surface from Finish.
+func (c *Client) assembleChunk(rawParams json.RawMessage) {
+	n := &types.ProgressNotification{Method: types.MethodProgress}
+	if err := json.Unmarshal(rawParams, &n.Params); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	assembler, ok := c.assemblers[n.Params.ProgressToken]
+	c.mu.Unlock()
+	if ok {
+		_ = assembler.Add(n)
+	}
+}
+
+// handleRequest answers a request from the server
+func (c *Client) handleRequest(req types.JSONRPCRequest) {
+	var result interface{}
+	var err error
+
+	switch {
+	case req.Method == types.MethodPing:
+		result = struct{}{}
+	case req.Method == types.MethodCreateMessage && c.sampling != nil:
+		result, err = types.HandleCreateMessage(c.ctx, c.sampling, req.Params)
+	default:
+		err = &types.ErrorInfo{
+			Code:    types.ErrMethodNotFound,
+			Message: fmt.Sprintf("Method not found: %s", req.Method),
+		}
+	}
+
+	resp := types.JSONRPCResponse{JSONRPC: types.JSONRPCVersion, ID: req.ID}
+	if err != nil {
+		var info *types.ErrorInfo
+		if !errors.As(err, &info) {
+			info = &types.ErrorInfo{Code: types.ErrInternal, Message: err.Error()}
+		}
+		resp.Error = info
+	} else if resp.Result, err = json.Marshal(result); err != nil {
+		resp.Result = nil
+		resp.Error = &types.ErrorInfo{Code: types.ErrInternal, Message: "marshaling result failed"}
+	}
+
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = c.transport.Write(c.ctx, msg)
+}
+
+// fail records why the client stopped and wakes every pending call
+func (c *Client) fail(err error) {
+	c.calls.Close(err)
+}
+
+func supportedVersion(version string) bool {
+	for _, v := range types.SupportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+/* Usage Example:
+func ExampleClient() {
+    cmd := exec.Command("my-mcp-server")
+    stdin, _ := cmd.StdinPipe()
+    stdout, _ := cmd.StdoutPipe()
+    if err := cmd.Start(); err != nil {
+        log.Fatal(err)
+    }
+
+    transport, err := types.NewStdioTransport(stdout, stdin)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    info, _ := types.NewImplementation("example-client", "1.0.0")
+    c, err := client.New(transport, *info,
+        client.WithNotificationHandler(func(method string, params json.RawMessage) {
+            log.Printf("notification %s: %s", method, params)
+        }),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer c.Close()
+
+    if _, err := c.Initialize(ctx); err != nil {
+        log.Fatal(err)
+    }
+
+    tools, err := c.ListTools(ctx)
+    if err != nil {
+        log.Fatal(err)
+    }
+    for _, tool := range tools.Tools {
+        fmt.Println(tool.Name)
+    }
+
+    result, err := c.CallTool(ctx, "search", map[string]interface{}{"query": "mcp"})
+    if err != nil {
+        var rpcErr *types.ErrorInfo
+        if errors.As(err, &rpcErr) {
+            log.Fatalf("server error: %v", rpcErr)
+        }
+        log.Fatal(err)
+    }
+    fmt.Println(result.Content)
+}
+*/
@@ -0,0 +1,503 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/client"
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// message is any message a client sends
+type message struct {
+	ID     *types.RequestID `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+	Result json.RawMessage  `json:"result"`
+	Error  *types.ErrorInfo `json:"error"`
+}
+
+// fakeServer plays the server's side of a connection message by message, so
+// tests choose exactly what the client receives
+type fakeServer struct {
+	t         *testing.T
+	transport types.Transport
+	messages  chan message
+}
+
+// connect returns a client talking to a fake server over an in-memory pipe
+func connect(t *testing.T, opts ...client.Option) (*client.Client, *fakeServer) {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	transport, err := types.NewStdioTransport(serverIn, serverOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTransport, err := types.NewStdioTransport(clientIn, clientOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-client", "1.0.0")
+	c, err := client.New(clientTransport, *info, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &fakeServer{t: t, transport: transport, messages: make(chan message)}
+	go func() {
+		defer close(f.messages)
+		for {
+			data, err := transport.Read(ctx)
+			if err != nil {
+				return
+			}
+			var m message
+			if err := json.Unmarshal(data, &m); err != nil {
+				continue
+			}
+			select {
+			case f.messages <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		c.Close()
+		cancel()
+		serverIn.Close()
+		clientIn.Close()
+	})
+	return c, f
+}
+
+// send writes a raw message to the client
+func (f *fakeServer) send(format string, args ...interface{}) {
+	f.t.Helper()
+	if err := f.transport.Write(context.Background(), []byte(fmt.Sprintf(format, args...))); err != nil {
+		f.t.Fatalf("sending message: %v", err)
+	}
+}
+
+// next returns the next message from the client
+func (f *fakeServer) next() message {
+	f.t.Helper()
+	select {
+	case m, ok := <-f.messages:
+		if !ok {
+			f.t.Fatal("client closed the connection")
+		}
+		return m
+	case <-time.After(5 * time.Second):
+		f.t.Fatal("timed out waiting for a message")
+	}
+	return message{}
+}
+
+// expect returns the next message, failing unless it is a request or
+// notification for method
+func (f *fakeServer) expect(method string) message {
+	f.t.Helper()
+	m := f.next()
+	if m.Method != method {
+		f.t.Fatalf("got %+v, want %s", m, method)
+	}
+	return m
+}
+
+// reply answers a request with a raw result
+func (f *fakeServer) reply(m message, result string) {
+	f.t.Helper()
+	id, err := json.Marshal(m.ID)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.send(`{"jsonrpc":"2.0","id":%s,"result":%s}`, id, result)
+}
+
+// initialize completes the handshake for c
+func (f *fakeServer) initialize(c *client.Client) {
+	f.t.Helper()
+	done := async(func() error {
+		_, err := c.Initialize(context.Background())
+		return err
+	})
+	f.reply(f.expect(types.MethodInitialize), fmt.Sprintf(
+		`{"protocolVersion":%q,"capabilities":{},"serverInfo":{"name":"test-server","version":"1.0.0"}}`,
+		types.LatestProtocolVersion))
+	f.expect(types.MethodInitialized)
+	if err := wait(f.t, done); err != nil {
+		f.t.Fatalf("Initialize: %v", err)
+	}
+}
+
+// async runs call on its own goroutine, returning its error on the channel
+func async(call func() error) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- call() }()
+	return done
+}
+
+// wait returns the error from an async call
+func wait(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the call to return")
+		return nil
+	}
+}
+
+// serve runs srv over an in-memory pipe and returns an initialized client for it
+func serve(t *testing.T, srv *server.Server) *client.Client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	serverTransport, err := types.NewStdioTransport(serverIn, serverOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTransport, err := types.NewStdioTransport(clientIn, clientOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.Serve(ctx, serverTransport)
+	}()
+
+	info, _ := types.NewImplementation("test-client", "1.0.0")
+	c, err := client.New(clientTransport, *info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		c.Close()
+		cancel()
+		serverIn.Close()
+		clientIn.Close()
+		<-done
+	})
+
+	if _, err := c.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return c
+}
+
+func TestPagination(t *testing.T) {
+	listTools := func(c *client.Client) (int, error) {
+		result, err := c.ListTools(context.Background())
+		if err != nil {
+			return 0, err
+		}
+		return len(result.Tools), nil
+	}
+	readResource := func(c *client.Client) (int, error) {
+		result, err := c.ReadResource(context.Background(), "file:///log.txt")
+		if err != nil {
+			return 0, err
+		}
+		return len(result.Contents), nil
+	}
+	tool := func(name string) string {
+		return fmt.Sprintf(`{"name":%q,"inputSchema":{"type":"object"}}`, name)
+	}
+	text := `{"uri":"file:///log.txt","text":"line"}`
+
+	tests := []struct {
+		name    string
+		method  string
+		call    func(*client.Client) (int, error)
+		pages   []string
+		want    int
+		wantErr string
+	}{
+		{
+			name:   "tools in one page",
+			method: types.MethodListTools,
+			call:   listTools,
+			pages:  []string{`{"tools":[` + tool("a") + `]}`},
+			want:   1,
+		},
+		{
+			name:   "tools across pages",
+			method: types.MethodListTools,
+			call:   listTools,
+			pages: []string{
+				`{"tools":[` + tool("a") + `],"nextCursor":"p2"}`,
+				`{"tools":[` + tool("b") + `,` + tool("c") + `]}`,
+			},
+			want: 3,
+		},
+		{
+			name:   "tools with a repeated cursor",
+			method: types.MethodListTools,
+			call:   listTools,
+			pages: []string{
+				`{"tools":[` + tool("a") + `],"nextCursor":"p2"}`,
+				`{"tools":[` + tool("b") + `],"nextCursor":"p2"}`,
+			},
+			wantErr: `server repeated cursor "p2"`,
+		},
+		{
+			name:   "resource contents across pages",
+			method: types.MethodReadResource,
+			call:   readResource,
+			pages: []string{
+				`{"contents":[` + text + `],"nextCursor":"p2"}`,
+				`{"contents":[` + text + `],"nextCursor":"p3"}`,
+				`{"contents":[` + text + `]}`,
+			},
+			want: 3,
+		},
+		{
+			name:   "resource contents with a repeated cursor",
+			method: types.MethodReadResource,
+			call:   readResource,
+			pages: []string{
+				`{"contents":[` + text + `],"nextCursor":"p2"}`,
+				`{"contents":[` + text + `],"nextCursor":"p3"}`,
+				`{"contents":[` + text + `],"nextCursor":"p2"}`,
+			},
+			wantErr: `server repeated cursor "p2"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, f := connect(t)
+			f.initialize(c)
+
+			var got int
+			done := async(func() (err error) {
+				got, err = tt.call(c)
+				return err
+			})
+
+			var cursor *string
+			for i, page := range tt.pages {
+				m := f.expect(tt.method)
+				var params struct {
+					Cursor *string `json:"cursor"`
+				}
+				if err := json.Unmarshal(m.Params, &params); err != nil {
+					t.Fatal(err)
+				}
+				if (params.Cursor == nil) != (cursor == nil) || (cursor != nil && *params.Cursor != *cursor) {
+					t.Fatalf("page %d: cursor = %v, want %v", i+1, params.Cursor, cursor)
+				}
+				f.reply(m, page)
+
+				var next struct {
+					NextCursor *string `json:"nextCursor"`
+				}
+				if err := json.Unmarshal([]byte(page), &next); err != nil {
+					t.Fatal(err)
+				}
+				cursor = next.NextCursor
+			}
+
+			err := wait(t, done)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d items, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallCancelledByContext(t *testing.T) {
+	c, f := connect(t)
+	f.initialize(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := async(func() error {
+		_, err := c.CallTool(ctx, "slow", nil)
+		return err
+	})
+	req := f.expect(types.MethodCallTool)
+	cancel()
+
+	n := f.expect(types.MethodCancelled)
+	var params types.CancelledParams
+	if err := json.Unmarshal(n.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.RequestID != *req.ID {
+		t.Errorf("cancelled request %s, want %s", params.RequestID, req.ID)
+	}
+	if err := wait(t, done); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCallCancelledByServer(t *testing.T) {
+	c, f := connect(t)
+	f.initialize(c)
+
+	done := async(func() error {
+		_, err := c.CallTool(context.Background(), "slow", nil)
+		return err
+	})
+	req := f.expect(types.MethodCallTool)
+	id, _ := json.Marshal(req.ID)
+	f.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":%s,"reason":"server shutting down"}}`, id)
+
+	err := wait(t, done)
+	var info *types.ErrorInfo
+	if !errors.As(err, &info) || !strings.Contains(info.Message, "server shutting down") {
+		t.Errorf("err = %v, want the server's cancellation reason", err)
+	}
+}
+
+func TestServerRequests(t *testing.T) {
+	sampling := client.WithSamplingHandler(types.SamplingHandlerFunc(func(ctx context.Context, params types.CreateMessageParams) (*types.CreateMessageResult, error) {
+		return &types.CreateMessageResult{
+			Role:    types.RoleAssistant,
+			Content: *types.NewTextContent("hello", nil),
+			Model:   "test-model",
+		}, nil
+	}))
+	createMessage := `{"messages":[{"role":"user","content":{"type":"text","text":"hi"}}],"maxTokens":10}`
+
+	tests := []struct {
+		name     string
+		opts     []client.Option
+		method   string
+		params   string
+		wantCode int
+		want     string
+	}{
+		{name: "ping", method: types.MethodPing, params: `{}`, want: `{}`},
+		{name: "sampling", opts: []client.Option{sampling}, method: types.MethodCreateMessage, params: createMessage, want: "test-model"},
+		{name: "sampling without a handler", method: types.MethodCreateMessage, params: createMessage, wantCode: types.ErrMethodNotFound},
+		{name: "invalid sampling params", opts: []client.Option{sampling}, method: types.MethodCreateMessage, params: `{"messages":[],"maxTokens":10}`, wantCode: types.ErrInternal},
+		{name: "unknown method", method: "roots/unknown", params: `{}`, wantCode: types.ErrMethodNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, f := connect(t, tt.opts...)
+			f.initialize(c)
+
+			f.send(`{"jsonrpc":"2.0","id":"s1","method":%q,"params":%s}`, tt.method, tt.params)
+			m := f.next()
+			if m.ID == nil || *m.ID != types.NewStringRequestID("s1") {
+				t.Fatalf("got %+v, want the response to s1", m)
+			}
+			if tt.wantCode != 0 {
+				if m.Error == nil || m.Error.Code != tt.wantCode {
+					t.Fatalf("error = %v, want code %d", m.Error, tt.wantCode)
+				}
+				return
+			}
+			if m.Error != nil {
+				t.Fatalf("request failed: %v", m.Error)
+			}
+			if !strings.Contains(string(m.Result), tt.want) {
+				t.Errorf("result = %s, want it to contain %s", m.Result, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadResourceStream(t *testing.T) {
+	blob := bytes.Repeat([]byte("0123456789"), 1000)
+	store := types.NewResourceStore()
+	streamed, _ := types.NewResource("file:///data.bin", "data")
+	err := store.RegisterStream(*streamed, func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(blob)), nil
+	}, types.WithStreamChunkSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inline, _ := types.NewResource("file:///note.txt", "note")
+	err = store.Register(*inline, func() (*types.ReadResourceResult, error) {
+		text := "inline"
+		return &types.ReadResourceResult{Contents: []types.ResourceContent{{URI: "file:///note.txt", Text: &text}}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info, server.WithResources(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := serve(t, srv)
+
+	tests := []struct {
+		name    string
+		uri     string
+		written []byte
+	}{
+		{name: "streamed blob", uri: "file:///data.bin", written: blob},
+		{name: "inline text", uri: "file:///note.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			result, err := c.ReadResourceStream(context.Background(), tt.uri, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.written) {
+				t.Errorf("wrote %d bytes, want %d", buf.Len(), len(tt.written))
+			}
+			if len(result.Contents) != 1 {
+				t.Fatalf("got %d contents, want 1", len(result.Contents))
+			}
+			if stream := result.Contents[0].Stream; (stream != nil) != (tt.written != nil) {
+				t.Errorf("stream = %+v, want streamed %v", stream, tt.written != nil)
+			}
+		})
+	}
+}
+
+func TestCloseFailsPendingCalls(t *testing.T) {
+	c, f := connect(t)
+	f.initialize(c)
+
+	done := async(func() error {
+		_, err := c.CallTool(context.Background(), "slow", nil)
+		return err
+	})
+	f.expect(types.MethodCallTool)
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait(t, done); !errors.Is(err, client.ErrClosed) {
+		t.Errorf("pending call: err = %v, want %v", err, client.ErrClosed)
+	}
+	if _, err := c.ListTools(context.Background()); !errors.Is(err, client.ErrClosed) {
+		t.Errorf("later call: err = %v, want %v", err, client.ErrClosed)
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// Authorizer decides whether a request may run. It is consulted after the
+// method is resolved and before its handler is called. Denials that are not
+// already an *ErrorInfo are reported to the client as an unauthorized error.
+type Authorizer interface {
+	Authorize(ctx context.Context, method string, params json.RawMessage) error
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(ctx context.Context, method string, params json.RawMessage) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, method string, params json.RawMessage) error {
+	return f(ctx, method, params)
+}
+
+// ErrNotAllowed is returned by MethodAllowlist for methods not on the list
+var ErrNotAllowed = errors.New("method not allowed")
+
+// AllowAll permits every request. It is the default Authorizer.
+func AllowAll() Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, method string, params json.RawMessage) error {
+		return nil
+	})
+}
+
+// MethodAllowlist permits only the given methods, plus initialize and ping so
+// that clients can still connect
+func MethodAllowlist(methods ...string) Authorizer {
+	allowed := map[string]bool{
+		types.MethodInitialize: true,
+		types.MethodPing:       true,
+	}
+	for _, m := range methods {
+		allowed[m] = true
+	}
+
+	return AuthorizerFunc(func(ctx context.Context, method string, params json.RawMessage) error {
+		if !allowed[method] {
+			return ErrNotAllowed
+		}
+		return nil
+	})
+}
+
+// WithAuthorizer checks every request with authorizer before its handler runs
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(s *Server) error {
+		if authorizer == nil {
+			return errors.New("authorizer cannot be nil")
+		}
+		s.authorizer = authorizer
+		return nil
+	}
+}
+
+// authorize runs the authorizer, turning a denial into a JSON-RPC error
+func (s *Server) authorize(ctx context.Context, method string, params json.RawMessage) error {
+	err := s.authorizer.Authorize(ctx, method, params)
+	if err == nil {
+		return nil
+	}
+
+	var info *types.ErrorInfo
+	if errors.As(err, &info) {
+		return info
+	}
+	return types.NewUnauthorizedError(method)
+}
+
+/* Usage Example:
+func ExampleAuthorizer() {
+    // Only admins may call the deleteResource tool; everything else is open
+    authorizer := server.AuthorizerFunc(func(ctx context.Context, method string, params json.RawMessage) error {
+        if method != types.MethodCallTool {
+            return nil
+        }
+        var call types.CallToolParams
+        if err := json.Unmarshal(params, &call); err != nil {
+            return err
+        }
+        if call.Name == "deleteResource" && !isAdmin(server.SessionFromContext(ctx)) {
+            return server.ErrNotAllowed
+        }
+        return nil
+    })
+
+    srv, err := server.New(*info, server.WithTools(tools), server.WithAuthorizer(authorizer))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // A read-only server
+    readOnly, err := server.New(*info, server.WithResources(store),
+        server.WithAuthorizer(server.MethodAllowlist(types.MethodListResources, types.MethodReadResource)),
+    )
+}
+*/
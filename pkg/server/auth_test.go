@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+func TestAuthorizer(t *testing.T) {
+	limited := server.AuthorizerFunc(func(ctx context.Context, method string, params json.RawMessage) error {
+		if method == "test/limited" {
+			return types.NewRateLimitError(30)
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		authorizer server.Authorizer
+		method     string
+		wantCode   int
+		wantReach  bool
+	}{
+		{name: "allowed method", authorizer: server.MethodAllowlist("test/allowed"), method: "test/allowed", wantReach: true},
+		{name: "method off the list", authorizer: server.MethodAllowlist("test/allowed"), method: "test/denied", wantCode: types.ErrUnauthorized},
+		{name: "ping is always allowed", authorizer: server.MethodAllowlist(), method: types.MethodPing},
+		{name: "denial as an ErrorInfo", authorizer: limited, method: "test/limited", wantCode: types.ErrRateLimited},
+		{name: "default allows all", method: "test/denied", wantReach: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []server.Option
+			if tt.authorizer != nil {
+				opts = append(opts, server.WithAuthorizer(tt.authorizer))
+			}
+			info, _ := types.NewImplementation("test-server", "1.0.0")
+			srv, err := server.New(*info, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reached := false
+			for _, method := range []string{"test/allowed", "test/denied", "test/limited"} {
+				handle(t, srv, method, func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+					reached = true
+					return "ran", nil
+				})
+			}
+
+			_, err = srv.Handle(context.Background(), tt.method, nil)
+			if tt.wantCode != 0 {
+				var info *types.ErrorInfo
+				if !errors.As(err, &info) || info.Code != tt.wantCode {
+					t.Errorf("err = %v, want code %d", err, tt.wantCode)
+				}
+			} else if err != nil {
+				t.Errorf("err = %v, want the request to run", err)
+			}
+			if reached != tt.wantReach {
+				t.Errorf("handler reached = %v, want %v", reached, tt.wantReach)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// DefaultBroadcastQueueSize is how many notifications may wait for a slow
+// session before further ones are dropped for it
+const DefaultBroadcastQueueSize = 64
+
+// ErrQueueFull is returned, joined per session, by Broadcast for sessions too
+// far behind to take another notification
+var ErrQueueFull = errors.New("session notification queue full")
+
+// BroadcasterOption configures NotificationBroadcaster
+type BroadcasterOption func(*NotificationBroadcaster) error
+
+// NotificationBroadcaster fans notifications out to the sessions added to it.
+// ResourceUpdatedNotifications go only to sessions subscribed to the URI;
+// every other notification goes to all sessions that finished initializing.
+// Each session has its own queue and writer goroutine, so notifications reach
+// a session in broadcast order and a slow or disconnected session never holds
+// up the others. A session whose write fails is removed. It is safe for
+// concurrent use.
+type NotificationBroadcaster struct {
+	queueSize int
+
+	mu          sync.Mutex
+	subscribers map[*Session]*subscriber
+}
+
+type subscriber struct {
+	uris  map[string]bool
+	queue chan types.Notification
+	// ctx is cancelled by Remove, abandoning a write in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewNotificationBroadcaster(opts ...BroadcasterOption) (*NotificationBroadcaster, error) {
+	b := &NotificationBroadcaster{
+		queueSize:   DefaultBroadcastQueueSize,
+		subscribers: make(map[*Session]*subscriber),
+	}
+
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, fmt.Errorf("applying broadcaster option: %w", err)
+		}
+	}
+
+	return b, nil
+}
+
+// Broadcaster options
+
+// WithBroadcastQueueSize sets how many notifications may be queued per session
+func WithBroadcastQueueSize(n int) BroadcasterOption {
+	return func(b *NotificationBroadcaster) error {
+		if n <= 0 {
+			return fmt.Errorf("broadcast queue size must be positive")
+		}
+		b.queueSize = n
+		return nil
+	}
+}
+
+// Add starts delivering broadcasts to the session. Adding a session twice has
+// no effect.
+func (b *NotificationBroadcaster) Add(session *Session) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[session]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscriber{
+		uris:   make(map[string]bool),
+		queue:  make(chan types.Notification, b.queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	b.subscribers[session] = sub
+	go b.deliver(session, sub)
+}
+
+// Remove stops delivering to the session, cancelling a write in progress, and
+// drops its subscriptions and queued notifications
+func (b *NotificationBroadcaster) Remove(session *Session) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, ok := b.subscribers[session]; ok {
+		sub.cancel()
+		delete(b.subscribers, session)
+	}
+}
+
+// Subscribe sends the session ResourceUpdatedNotifications for uri
+func (b *NotificationBroadcaster) Subscribe(session *Session, uri string) error {
+	if uri == "" {
+		return fmt.Errorf("resource URI cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, ok := b.subscribers[session]
+	if !ok {
+		return fmt.Errorf("session is not connected")
+	}
+	sub.uris[uri] = true
+	return nil
+}
+
+// Unsubscribe stops ResourceUpdatedNotifications for uri to the session
+func (b *NotificationBroadcaster) Unsubscribe(session *Session, uri string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, ok := b.subscribers[session]; ok {
+		delete(sub.uris, uri)
+	}
+}
+
+// Broadcast queues n for every session it is addressed to and returns without
+// waiting for the writes. Sessions whose queue is full miss the notification
+// and are reported with ErrQueueFull.
+func (b *NotificationBroadcaster) Broadcast(n types.Notification) error {
+	if n == nil {
+		return fmt.Errorf("notification cannot be nil")
+	}
+
+	var uri string
+	updated, isUpdate := n.(*types.ResourceUpdatedNotification)
+	if isUpdate {
+		uri = updated.Params.URI
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for session, sub := range b.subscribers {
+		if isUpdate && !sub.uris[uri] {
+			continue
+		}
+		select {
+		case sub.queue <- n:
+		default:
+			errs = append(errs, fmt.Errorf("notifying client %s: %w", session.ClientInfo().Name, ErrQueueFull))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver writes queued notifications to the session until it is removed
+func (b *NotificationBroadcaster) deliver(session *Session, sub *subscriber) {
+	for {
+		select {
+		case <-sub.ctx.Done():
+			return
+		case n := <-sub.queue:
+			if session.state.State() != types.SessionReady {
+				continue
+			}
+			if err := session.Notify(sub.ctx, n); err != nil {
+				b.Remove(session)
+				return
+			}
+		}
+	}
+}
+
+/* Usage Example:
+func ExampleNotificationBroadcaster() {
+    broadcaster, err := server.NewNotificationBroadcaster(server.WithBroadcastQueueSize(128))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Serve adds and removes sessions on its own when the broadcaster is
+    // passed with WithBroadcaster; resources/subscribe requests register URIs
+    srv, err := server.New(*info, server.WithResources(store), server.WithBroadcaster(broadcaster))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Reaches only the sessions subscribed to the file
+    updated, _ := types.NewResourceUpdatedNotification("file:///notes.txt")
+    if err := srv.Notify(updated); err != nil {
+        log.Print(err)
+    }
+}
+*/
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// Middleware wraps a Handler to add behaviour around every request, such as
+// logging, authorization or metrics
+type Middleware func(next Handler) Handler
+
+// Use adds middleware around the dispatcher. The first middleware passed to
+// the first call of Use is outermost. Requests rejected by the session, for
+// example before initialization, never reach the chain.
+func (s *Server) Use(mw ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.middleware = append(s.middleware, mw...)
+
+	var h Handler = HandlerFunc(s.Handle)
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	s.chain = h
+}
+
+// handler returns the dispatcher wrapped in the middleware chain
+func (s *Server) handler() Handler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.chain == nil {
+		return HandlerFunc(s.Handle)
+	}
+	return s.chain
+}
+
+// LoggingMiddleware sends the client a log message for every request with the
+// method, duration and any error. Successful requests are logged at level and
+// failed ones at error, subject to the level the client set.
+func LoggingMiddleware(level types.LoggingLevel) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+			start := time.Now()
+			result, err := next.Handle(ctx, method, params)
+
+			session := SessionFromContext(ctx)
+			if session == nil {
+				return result, err
+			}
+
+			data := map[string]interface{}{
+				"method":     method,
+				"durationMs": time.Since(start).Milliseconds(),
+			}
+			logLevel := level
+			if err != nil {
+				data["error"] = err.Error()
+				logLevel = types.LogLevelError
+			}
+			// logging must not change the outcome of the request
+			_ = session.Log(logLevel, data, types.WithLogger("mcp"))
+
+			return result, err
+		})
+	}
+}
+
+/* Usage Example:
+func ExampleMiddleware() {
+    srv, err := server.New(*info, server.WithTools(tools))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    timing := func(next server.Handler) server.Handler {
+        return server.HandlerFunc(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+            start := time.Now()
+            defer func() { requestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds()) }()
+            return next.Handle(ctx, method, params)
+        })
+    }
+
+    // Every request is timed, then logged to the client
+    srv.Use(timing, server.LoggingMiddleware(types.LogLevelDebug))
+}
+*/
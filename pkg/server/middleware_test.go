@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := newServer(t)
+	var calls []string
+	record := func(name string) server.Middleware {
+		return func(next server.Handler) server.Handler {
+			return server.HandlerFunc(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+				calls = append(calls, name+" before")
+				result, err := next.Handle(ctx, method, params)
+				calls = append(calls, name+" after")
+				return result, err
+			})
+		}
+	}
+	handle(t, srv, "test/work", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		calls = append(calls, "handler")
+		return "done", nil
+	})
+
+	srv.Use(record("first"), record("second"))
+	srv.Use(record("third"))
+
+	if _, err := srv.Handle(context.Background(), "test/work", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Handle bypasses the chain; sessions run it
+	if !reflect.DeepEqual(calls, []string{"handler"}) {
+		t.Fatalf("calls = %v, want only the handler", calls)
+	}
+
+	p := dial(t, srv)
+	p.initialize()
+	calls = nil
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/work"}`)
+	if m := p.next(); m.Error != nil {
+		t.Fatalf("request failed: %v", m.Error)
+	}
+
+	want := []string{
+		"first before", "second before", "third before",
+		"handler",
+		"third after", "second after", "first after",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	srv := newServer(t)
+	handle(t, srv, "test/fail", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return nil, types.NewUnauthorizedError(method)
+	})
+	srv.Use(server.LoggingMiddleware(types.LogLevelInfo))
+	p := dial(t, srv)
+	p.initialize()
+
+	tests := []struct {
+		name      string
+		method    string
+		wantLevel types.LoggingLevel
+		wantError bool
+	}{
+		{name: "success", method: types.MethodPing, wantLevel: types.LogLevelInfo},
+		{name: "failure", method: "test/fail", wantLevel: types.LogLevelError, wantError: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := p.on(t)
+			p.send(`{"jsonrpc":"2.0","id":%d,"method":%q}`, i+1, tt.method)
+
+			// the log message is sent before the response
+			m := p.next()
+			if m.Method != types.MethodLoggingMessage {
+				t.Fatalf("got %+v, want a log message", m)
+			}
+			var params struct {
+				Level  types.LoggingLevel     `json:"level"`
+				Logger string                 `json:"logger"`
+				Data   map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(m.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			if params.Level != tt.wantLevel || params.Logger != "mcp" || params.Data["method"] != tt.method {
+				t.Errorf("log message = %+v, want %s for %s", params, tt.wantLevel, tt.method)
+			}
+			if _, ok := params.Data["error"]; ok != tt.wantError {
+				t.Errorf("logged error = %v, want one: %v", params.Data["error"], tt.wantError)
+			}
+
+			if m := p.next(); m.ID == nil || *m.ID != types.NewIntRequestID(int64(i+1)) {
+				t.Errorf("got %+v, want the response", m)
+			}
+		})
+	}
+}
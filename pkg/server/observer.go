@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// Observer is told about every request a server handles, e.g. to record
+// counters and latencies with a metrics library. Calls come from the request's
+// goroutine, so implementations must be safe for concurrent use and should not
+// block.
+type Observer interface {
+	// RequestStarted is called when a request arrives, before any checks
+	RequestStarted(method string)
+	// RequestFinished is called with the time taken and the error sent to the
+	// client, if any. Cancelled requests report why they were cancelled, even
+	// when no response is sent, and requests whose handler panicked report a
+	// *PanicError.
+	RequestFinished(method string, dur time.Duration, err error)
+}
+
+// PanicError is reported to RequestFinished when a request's handler,
+// middleware or registry panics. The client receives a generic internal error.
+type PanicError struct {
+	Method string
+	Value  interface{} // the value passed to panic
+	Stack  []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic handling %s: %v", e.Method, e.Value)
+}
+
+// NopObserver ignores every request. It is the default Observer.
+type NopObserver struct{}
+
+func (NopObserver) RequestStarted(method string)                                {}
+func (NopObserver) RequestFinished(method string, dur time.Duration, err error) {}
+
+// WithObserver reports every request to observer
+func WithObserver(observer Observer) Option {
+	return func(s *Server) error {
+		if observer == nil {
+			return fmt.Errorf("observer cannot be nil")
+		}
+		s.observer = observer
+		return nil
+	}
+}
+
+/* Usage Example:
+type promObserver struct {
+    inflight *prometheus.GaugeVec
+    duration *prometheus.HistogramVec
+}
+
+func (o promObserver) RequestStarted(method string) {
+    o.inflight.WithLabelValues(method).Inc()
+}
+
+func (o promObserver) RequestFinished(method string, dur time.Duration, err error) {
+    o.inflight.WithLabelValues(method).Dec()
+    o.duration.WithLabelValues(method, strconv.FormatBool(err == nil)).Observe(dur.Seconds())
+}
+
+func ExampleObserver() {
+    srv, err := server.New(*info, server.WithTools(tools), server.WithObserver(promObserver{inflight, duration}))
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+*/
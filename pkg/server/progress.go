@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+type (
+	progressKey   struct{}
+	toolStreamKey struct{}
+)
+
+// ProgressFromContext returns the progress reporter for the request a handler
+// is serving, or nil if the client sent no progress token with it
+func ProgressFromContext(ctx context.Context) *Progress {
+	progress, _ := ctx.Value(progressKey{}).(*Progress)
+	return progress
+}
+
+// Progress sends progress notifications for one request to the client that
+// made it, under the progress token from the request's _meta. It is safe for
+// concurrent use.
+type Progress struct {
+	token   types.ProgressToken
+	tracker *types.ProgressTracker
+	emitter *types.Emitter
+}
+
+// Token returns the progress token the client sent with the request
+func (p *Progress) Token() types.ProgressToken {
+	return p.token
+}
+
+// Tracker returns the tracker behind p, e.g. to check whether the request has stalled
+func (p *Progress) Tracker() *types.ProgressTracker {
+	return p.tracker
+}
+
+// Update records new progress and sends it to the client. Progress must
+// increase with every update.
+func (p *Progress) Update(ctx context.Context, progress float64) error {
+	n, err := p.tracker.Update(progress)
+	if err != nil {
+		return err
+	}
+	return p.emitter.EmitContext(ctx, n)
+}
+
+// Notify sends a notification tied to the request's progress token, such as
+// a partial tool result
+func (p *Progress) Notify(ctx context.Context, n types.Notification) error {
+	return p.emitter.EmitContext(ctx, n)
+}
+
+// ToolStreamFromContext returns the stream a tool handler can send partial
+// results on, or nil if the client sent no progress token with the call. The
+// server closes the stream with the handler's result.
+func ToolStreamFromContext(ctx context.Context) *types.ToolResultStream {
+	stream, _ := ctx.Value(toolStreamKey{}).(*types.ToolResultStream)
+	return stream
+}
+
+// callToolStreaming runs a tool call with a result stream in its context and
+// forwards the partial results to the client under the call's progress token.
+// Every partial result is sent before the call returns, and so before the
+// response.
+func (s *Server) callToolStreaming(ctx context.Context, req *types.CallToolRequest, progress *Progress) (*types.CallToolResult, error) {
+	stream, err := types.NewToolResultStream(progress.Token(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for n := range stream.Chunks() {
+			// keep draining after a failed send so the handler never blocks
+			_ = progress.Notify(ctx, n)
+		}
+	}()
+
+	result, err := s.tools.Call(context.WithValue(ctx, toolStreamKey{}, stream), req)
+	final := result
+	if final == nil {
+		final = types.NewToolResultError("tool call failed")
+	}
+	_ = stream.Close(final)
+	<-forwarded
+	return result, err
+}
+
+// withProgress attaches a Progress to ctx when the request's _meta carries a
+// progress token
+func (s *Session) withProgress(ctx context.Context, req types.JSONRPCRequest) context.Context {
+	var params struct {
+		Meta map[string]interface{} `json:"_meta"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(req.Params))
+	dec.UseNumber() // keeps tokens above 2^53 exact
+	if err := dec.Decode(&params); err != nil {
+		return ctx
+	}
+
+	token, ok := types.ExtractProgressToken(params.Meta)
+	if !ok {
+		return ctx
+	}
+	tracker, err := types.NewProgressTracker(token)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, &Progress{token: token, tracker: tracker, emitter: s.emitter})
+}
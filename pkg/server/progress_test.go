@@ -0,0 +1,137 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+func TestProgressFromContext(t *testing.T) {
+	srv := newServer(t)
+	err := srv.HandleMethod("test/work", server.HandlerFunc(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		progress := server.ProgressFromContext(ctx)
+		if progress == nil {
+			return "untracked", nil
+		}
+		if err := progress.Update(ctx, 50); err != nil {
+			return nil, err
+		}
+		return "tracked", nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := dial(t, srv)
+	p.initialize()
+
+	tests := []struct {
+		name   string
+		params string
+		token  types.ProgressToken
+		want   string
+	}{
+		{name: "numeric token", params: `{"_meta":{"progressToken":7}}`, token: 7, want: "tracked"},
+		{name: "string token", params: `{"_meta":{"progressToken":"8"}}`, token: 8, want: "tracked"},
+		{name: "large token", params: `{"_meta":{"progressToken":9007199254740993}}`, token: 9007199254740993, want: "tracked"},
+		{name: "no token", params: `{}`, want: "untracked"},
+		{name: "no params", params: `null`, want: "untracked"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := p.on(t)
+			p.send(`{"jsonrpc":"2.0","id":%d,"method":"test/work","params":%s}`, i+1, tt.params)
+
+			m := p.next()
+			if tt.want == "tracked" {
+				if m.Method != types.MethodProgress {
+					t.Fatalf("got %+v, want a progress notification", m)
+				}
+				var params types.ProgressParams
+				if err := json.Unmarshal(m.Params, &params); err != nil {
+					t.Fatal(err)
+				}
+				if params.ProgressToken != tt.token || params.Progress != 50 {
+					t.Errorf("progress = %+v, want token %d at 50", params, tt.token)
+				}
+				m = p.next()
+			}
+
+			var result string
+			if m.Error != nil {
+				t.Fatalf("request failed: %v", m.Error)
+			}
+			if err := json.Unmarshal(m.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.want {
+				t.Errorf("result = %q, want %q", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallToolForwardsPartialResults(t *testing.T) {
+	tools, err := types.NewToolRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool, err := types.NewTool("count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tools.Register(*tool, types.ToolHandlerFunc(func(ctx context.Context, params types.CallToolParams) (*types.CallToolResult, error) {
+		stream := server.ToolStreamFromContext(ctx)
+		if stream == nil {
+			return types.NewToolResultText("unstreamed"), nil
+		}
+		for _, text := range []string{"one", "two"} {
+			if err := stream.Send(ctx, *types.NewTextContent(text, nil)); err != nil {
+				return nil, err
+			}
+		}
+		return types.NewToolResultText("done"), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info, server.WithTools(tools))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := dial(t, srv)
+	p.initialize()
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"count","_meta":{"progressToken":5}}}`)
+	for i, want := range []string{"one", "two"} {
+		m := p.next()
+		if m.Method != types.MethodPartialToolResult {
+			t.Fatalf("got %+v, want a partial result", m)
+		}
+		var partial types.PartialCallToolResult
+		if err := json.Unmarshal(m.Params, &partial); err != nil {
+			t.Fatal(err)
+		}
+		if partial.ProgressToken != 5 || partial.Sequence != i+1 || partial.Content[0].TextContent.Text != want {
+			t.Errorf("partial result %d = %+v, want %q under token 5", i+1, partial, want)
+		}
+	}
+	if m := p.next(); m.Error != nil || m.ID == nil {
+		t.Fatalf("got %+v, want the call's response", m)
+	}
+
+	// without a progress token the handler gets no stream
+	p.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"count"}}`)
+	var result types.CallToolResult
+	if m := p.next(); m.Error != nil || json.Unmarshal(m.Result, &result) != nil {
+		t.Fatalf("got %+v, want the call's response", m)
+	}
+	if text := result.Content[0].TextContent.Text; text != "unstreamed" {
+		t.Errorf("result = %q, want unstreamed", text)
+	}
+}
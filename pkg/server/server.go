@@ -0,0 +1,581 @@
+// Package server implements an MCP server on top of the protocol types in
+// pkg/types. A Server advertises capabilities for the registries it was given,
+// routes requests to them and sends notifications to connected clients.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// Handler handles requests for one or more methods, returning the value to
+// send as the result
+type Handler interface {
+	Handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+}
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	return f(ctx, method, params)
+}
+
+// Option configures Server
+type Option func(*Server) error
+
+// Server is a transport-agnostic MCP server. Each call to Serve runs one
+// session; notifications sent through Notify are fanned out to the sessions
+// by a NotificationBroadcaster. It is safe for concurrent use.
+type Server struct {
+	info         types.Implementation
+	instructions *string
+	logLevel     types.LoggingLevel
+
+	tools       *types.ToolRegistry
+	resources   *types.ResourceStore
+	prompts     *types.PromptRegistry
+	completions *types.CompletionRegistry
+	broadcaster *NotificationBroadcaster
+	authorizer  Authorizer
+	timeouts    map[string]time.Duration
+	observer    Observer
+	watcher     *types.FileResourceWatcher
+	stopWatcher context.CancelFunc
+
+	mu         sync.RWMutex
+	methods    map[string]Handler
+	middleware []Middleware
+	chain      Handler // nil until Use is called
+	sessions   map[*Session]struct{}
+	closed     bool
+}
+
+// ErrServerClosed is returned by Serve after Shutdown
+var ErrServerClosed = errors.New("server closed")
+
+// New creates a server identifying itself as info
+func New(info types.Implementation, opts ...Option) (*Server, error) {
+	if info.Name == "" {
+		return nil, fmt.Errorf("server name cannot be empty")
+	}
+
+	s := &Server{
+		info:       info,
+		logLevel:   types.LogLevelInfo,
+		authorizer: AllowAll(),
+		observer:   NopObserver{},
+		sessions:   make(map[*Session]struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("applying server option: %w", err)
+		}
+	}
+
+	if s.broadcaster == nil {
+		var err error
+		s.broadcaster, err = NewNotificationBroadcaster()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.startWatcher(); err != nil {
+		return nil, err
+	}
+
+	s.methods = map[string]Handler{
+		types.MethodInitialize: HandlerFunc(s.initialize),
+		types.MethodPing:       HandlerFunc(ping),
+		types.MethodSetLevel:   HandlerFunc(s.setLevel),
+	}
+	if s.tools != nil {
+		s.methods[types.MethodListTools] = HandlerFunc(s.listTools)
+		s.methods[types.MethodCallTool] = HandlerFunc(s.callTool)
+	}
+	if s.resources != nil {
+		s.methods[types.MethodListResources] = HandlerFunc(s.listResources)
+		s.methods[types.MethodListResourceTemplates] = HandlerFunc(s.listResourceTemplates)
+		s.methods[types.MethodReadResource] = HandlerFunc(s.readResource)
+		s.methods[types.MethodSubscribe] = HandlerFunc(s.subscribe)
+		s.methods[types.MethodUnsubscribe] = HandlerFunc(s.subscribe)
+	}
+	if s.prompts != nil {
+		s.methods[types.MethodListPrompts] = HandlerFunc(s.listPrompts)
+		s.methods[types.MethodGetPrompt] = HandlerFunc(s.getPrompt)
+	}
+	if s.completions != nil {
+		s.methods[types.MethodComplete] = HandlerFunc(s.complete)
+	}
+
+	return s, nil
+}
+
+// Server options
+
+// WithTools serves tools/list and tools/call from the registry
+func WithTools(registry *types.ToolRegistry) Option {
+	return func(s *Server) error {
+		if registry == nil {
+			return fmt.Errorf("tool registry cannot be nil")
+		}
+		s.tools = registry
+		return nil
+	}
+}
+
+// WithResources serves resources/list, resources/templates/list and
+// resources/read from the store, and tracks resources/subscribe requests for
+// resource updates sent through Notify
+func WithResources(store *types.ResourceStore) Option {
+	return func(s *Server) error {
+		if store == nil {
+			return fmt.Errorf("resource store cannot be nil")
+		}
+		s.resources = store
+		return nil
+	}
+}
+
+// WithPrompts serves prompts/list and prompts/get from the registry
+func WithPrompts(registry *types.PromptRegistry) Option {
+	return func(s *Server) error {
+		if registry == nil {
+			return fmt.Errorf("prompt registry cannot be nil")
+		}
+		s.prompts = registry
+		return nil
+	}
+}
+
+// WithCompletions serves completion/complete from the registry
+func WithCompletions(registry *types.CompletionRegistry) Option {
+	return func(s *Server) error {
+		if registry == nil {
+			return fmt.Errorf("completion registry cannot be nil")
+		}
+		s.completions = registry
+		return nil
+	}
+}
+
+// WithBroadcaster fans out notifications with broadcaster, e.g. one
+// configured with WithBroadcastQueueSize or shared with other servers
+func WithBroadcaster(broadcaster *NotificationBroadcaster) Option {
+	return func(s *Server) error {
+		if broadcaster == nil {
+			return fmt.Errorf("broadcaster cannot be nil")
+		}
+		s.broadcaster = broadcaster
+		return nil
+	}
+}
+
+// WithInstructions sets the instructions returned from initialize
+func WithInstructions(instructions string) Option {
+	return func(s *Server) error {
+		s.instructions = &instructions
+		return nil
+	}
+}
+
+// WithLogLevel sets the level sessions log at until the client sends
+// logging/setLevel. The default is info.
+func WithLogLevel(level types.LoggingLevel) Option {
+	return func(s *Server) error {
+		if _, err := types.ParseLoggingLevel(string(level)); err != nil {
+			return err
+		}
+		s.logLevel = level
+		return nil
+	}
+}
+
+// HandleMethod routes requests for method to h, replacing the built-in
+// handler if there is one
+func (s *Server) HandleMethod(method string, h Handler) error {
+	if method == "" {
+		return fmt.Errorf("method cannot be empty")
+	}
+	if h == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[method] = h
+	return nil
+}
+
+// Capabilities returns what the server advertises during initialize. Logging
+// is always supported; the other features follow the registries the server was
+// created with, including list-changed notifications sent through Notify.
+func (s *Server) Capabilities() types.ServerCapabilities {
+	opts := []types.ServerCapabilityOption{types.WithServerLogging()}
+	if s.tools != nil {
+		opts = append(opts, types.WithServerTools(true))
+	}
+	if s.resources != nil {
+		opts = append(opts, types.WithServerResources(true, true))
+	}
+	if s.prompts != nil {
+		opts = append(opts, types.WithServerPrompts(true))
+	}
+
+	caps, _ := types.NewServerCapabilities(opts...)
+	return *caps
+}
+
+// Handle dispatches a request to the handler registered for its method once
+// the authorizer allows it
+func (s *Server) Handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	s.mu.RLock()
+	h, ok := s.methods[method]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, &types.ErrorInfo{
+			Code:    types.ErrMethodNotFound,
+			Message: fmt.Sprintf("Method not found: %s", method),
+		}
+	}
+	if err := s.authorize(ctx, method, params); err != nil {
+		return nil, err
+	}
+	return h.Handle(ctx, method, params)
+}
+
+// Serve runs a session over transport until ctx is done, the transport fails
+// or the server is shut down. It returns nil when the peer closes the stream
+// and ErrServerClosed after Shutdown. In-flight requests are cancelled and
+// awaited before Serve returns. A read blocked in the transport ends only when
+// the transport is closed.
+func (s *Server) Serve(ctx context.Context, transport types.Transport) error {
+	session, err := newSession(s, transport)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.sessions[session] = struct{}{}
+	s.mu.Unlock()
+
+	s.broadcaster.Add(session)
+	defer func() {
+		s.broadcaster.Remove(session)
+		s.mu.Lock()
+		delete(s.sessions, session)
+		s.mu.Unlock()
+	}()
+
+	err = session.serve(ctx)
+	switch {
+	case s.isClosed():
+		return ErrServerClosed
+	case errors.Is(err, io.EOF):
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops the server gracefully: new sessions and requests are refused
+// while running requests finish. If ctx ends first, the remaining requests are
+// cancelled, their clients are sent a cancelled notification and Shutdown
+// returns ctx's error without waiting further. Every Serve call then returns
+// ErrServerClosed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.stopWatcher != nil {
+		s.stopWatcher()
+	}
+	sessions := make([]*Session, 0, len(s.sessions))
+	for session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		session.beginShutdown()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		for _, session := range sessions {
+			session.wg.Wait()
+		}
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		for _, session := range sessions {
+			session.cancelInflight()
+		}
+		err = ctx.Err()
+	}
+
+	for _, session := range sessions {
+		session.close()
+	}
+	return err
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.closed
+}
+
+// Notify queues a notification for the connected sessions: resource updates
+// for those subscribed to the resource, anything else, such as a list-changed
+// notification, for every session that finished initializing. See
+// NotificationBroadcaster.Broadcast.
+func (s *Server) Notify(n types.Notification) error {
+	return s.broadcaster.Broadcast(n)
+}
+
+// Built-in handlers
+
+func (s *Server) initialize(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("initialize requires a session")
+	}
+
+	req := &types.InitializeRequest{Method: method}
+	if err := decodeParams(params, &req.Params); err != nil {
+		return nil, err
+	}
+	version, err := session.state.HandleInitialize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []types.InitializeResultOption{}
+	if s.instructions != nil {
+		opts = append(opts, types.WithInstructions(*s.instructions))
+	}
+	result, err := types.NewInitializeResult(s.info, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.ProtocolVersion = version
+	result.Capabilities = s.Capabilities()
+	return result, nil
+}
+
+func ping(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	return struct{}{}, nil
+}
+
+func (s *Server) setLevel(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("logging/setLevel requires a session")
+	}
+
+	req := &types.SetLevelRequest{Method: method}
+	if err := decodeParams(params, &req.Params); err != nil {
+		return nil, err
+	}
+	if err := session.logger.ApplySetLevel(req); err != nil {
+		return nil, &types.ErrorInfo{Code: types.ErrInvalidParams, Message: err.Error()}
+	}
+	return struct{}{}, nil
+}
+
+// listTools pages through the registry, downgrading schemas for clients that
+// declared an older dialect
+func (s *Server) listTools(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	var page types.PaginatedParams
+	if err := decodeParams(params, &page); err != nil {
+		return nil, err
+	}
+	result, err := s.tools.List(page.Cursor, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := result.Tools
+	if session := SessionFromContext(ctx); session != nil {
+		if dialect, ok := types.SchemaDialectFromCapabilities(session.ClientCapabilities()); ok {
+			for i, tool := range tools {
+				tools[i].InputSchema = tool.InputSchema.Downgrade(dialect)
+				if tool.OutputSchema != nil {
+					output := tool.OutputSchema.Downgrade(dialect)
+					tools[i].OutputSchema = &output
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// callTool decodes the arguments with DecodeArguments, so that numbers reach
+// the registry's validation against the tool's InputSchema, and the handler,
+// as json.Number rather than float64: integers keep their precision and match
+// integer enums.
+func (s *Server) callTool(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	var raw struct {
+		Name      string                 `json:"name"`
+		Arguments json.RawMessage        `json:"arguments,omitempty"`
+		Meta      map[string]interface{} `json:"_meta,omitempty"`
+	}
+	if err := decodeParams(params, &raw); err != nil {
+		return nil, err
+	}
+	var args map[string]interface{}
+	if len(raw.Arguments) > 0 && string(raw.Arguments) != "null" {
+		var err error
+		if args, err = types.DecodeArguments(raw.Arguments, nil); err != nil {
+			return nil, &types.ErrorInfo{
+				Code:    types.ErrInvalidParams,
+				Message: fmt.Sprintf("Invalid params: %v", err),
+			}
+		}
+	}
+
+	req := &types.CallToolRequest{
+		Method: method,
+		Params: types.CallToolParams{Name: raw.Name, Arguments: args, Meta: raw.Meta},
+	}
+	if progress := ProgressFromContext(ctx); progress != nil {
+		return s.callToolStreaming(ctx, req, progress)
+	}
+	return s.tools.Call(ctx, req)
+}
+
+func (s *Server) listResources(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	resources := s.resources.Resources()
+	for i := range resources {
+		// lazy annotations are computed afresh for every listing
+		if err := resources[i].ResolveAnnotations(); err != nil {
+			return nil, err
+		}
+	}
+	return &types.ListResourcesResult{Resources: resources}, nil
+}
+
+func (s *Server) listResourceTemplates(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	return &types.ListResourceTemplatesResult{ResourceTemplates: s.resources.Templates()}, nil
+}
+
+// readResource streams large blobs when the client supplied a progress token
+func (s *Server) readResource(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	var req types.ReadResourceRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	progress := ProgressFromContext(ctx)
+	if progress == nil {
+		return s.resources.Read(&req)
+	}
+	return s.resources.ReadStreaming(ctx, &req, progress.Token(), func(n *types.ProgressNotification) error {
+		return progress.Notify(ctx, n)
+	})
+}
+
+// subscribe handles both resources/subscribe and resources/unsubscribe
+func (s *Server) subscribe(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("%s requires a session", method)
+	}
+
+	var req types.SubscribeParams
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	if req.URI == "" {
+		return nil, &types.ErrorInfo{Code: types.ErrInvalidParams, Message: "Resource URI cannot be empty"}
+	}
+
+	if method == types.MethodUnsubscribe {
+		s.broadcaster.Unsubscribe(session, req.URI)
+		return struct{}{}, nil
+	}
+	if err := s.broadcaster.Subscribe(session, req.URI); err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
+
+func (s *Server) listPrompts(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	return &types.ListPromptsResult{Prompts: s.prompts.Prompts()}, nil
+}
+
+func (s *Server) getPrompt(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	var req types.GetPromptRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	return s.prompts.Get(ctx, &req)
+}
+
+func (s *Server) complete(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	req := &types.CompleteRequest{Method: method}
+	if err := decodeParams(params, &req.Params); err != nil {
+		return nil, err
+	}
+	return s.completions.Complete(req)
+}
+
+// decodeParams unmarshals request params, reporting malformed params as an
+// invalid params error
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &types.ErrorInfo{
+			Code:    types.ErrInvalidParams,
+			Message: fmt.Sprintf("Invalid params: %v", err),
+		}
+	}
+	return nil
+}
+
+/* Usage Example:
+func ExampleServer() {
+    tools, _ := types.NewToolRegistry()
+    tool, _ := types.NewTool("echo", types.WithToolProperty("text", types.JSONSchema{Type: types.TypeString}))
+    tools.Register(*tool, types.ToolHandlerFunc(func(ctx context.Context, params types.CallToolParams) (*types.CallToolResult, error) {
+        text, _ := params.Arguments["text"].(string)
+        if session := server.SessionFromContext(ctx); session != nil {
+            session.Log(types.LogLevelDebug, "echoing "+text)
+        }
+        return &types.CallToolResult{Content: []types.Content{*types.NewTextContent(text, nil)}}, nil
+    }))
+
+    info, _ := types.NewImplementation("example-server", "1.0.0")
+    srv, err := server.New(*info, server.WithTools(tools))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    transport, err := types.NewStdioTransport(os.Stdin, os.Stdout)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if err := srv.Serve(ctx, transport); err != nil {
+        log.Fatal(err)
+    }
+}
+*/
@@ -0,0 +1,407 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/client"
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// connect serves srv over an in-memory pipe and returns a client for it
+func connect(t *testing.T, srv *server.Server, opts ...client.Option) *client.Client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	serverTransport, err := types.NewStdioTransport(serverIn, serverOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTransport, err := types.NewStdioTransport(clientIn, clientOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.Serve(ctx, serverTransport)
+	}()
+
+	info, _ := types.NewImplementation("test-client", "1.0.0")
+	c, err := client.New(clientTransport, *info, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		c.Close()
+		cancel()
+		serverIn.Close()
+		clientIn.Close()
+		<-done
+	})
+	return c
+}
+
+// message is any message a server sends
+type message struct {
+	ID     *types.RequestID `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+	Result json.RawMessage  `json:"result"`
+	Error  *types.ErrorInfo `json:"error"`
+}
+
+// peer speaks raw JSON-RPC to a server, for tests that need messages or
+// timing the Client does not offer
+type peer struct {
+	t         *testing.T
+	transport types.Transport
+	messages  chan message
+	done      chan struct{} // closed once Serve returns
+	served    *error        // what Serve returned
+}
+
+// dial serves srv over an in-memory pipe and returns a peer for it
+func dial(t *testing.T, srv *server.Server) *peer {
+	t.Helper()
+
+	serverIn, peerOut := io.Pipe()
+	peerIn, serverOut := io.Pipe()
+	serverTransport, err := types.NewStdioTransport(serverIn, serverOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, err := types.NewStdioTransport(peerIn, peerOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &peer{t: t, transport: transport, messages: make(chan message), done: make(chan struct{}), served: new(error)}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer close(p.done)
+		*p.served = srv.Serve(ctx, serverTransport)
+	}()
+
+	go func() {
+		defer close(p.messages)
+		for {
+			data, err := transport.Read(ctx)
+			if err != nil {
+				return
+			}
+			var m message
+			if err := json.Unmarshal(data, &m); err != nil {
+				continue
+			}
+			select {
+			case p.messages <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		serverIn.Close()
+		peerIn.Close()
+		<-p.done
+	})
+	return p
+}
+
+// on returns a copy of p that reports failures to t, for use in subtests
+func (p *peer) on(t *testing.T) *peer {
+	q := *p
+	q.t = t
+	return &q
+}
+
+// wait returns what Serve returned once the session ends
+func (p *peer) wait() error {
+	p.t.Helper()
+	select {
+	case <-p.done:
+		return *p.served
+	case <-time.After(5 * time.Second):
+		p.t.Fatal("timed out waiting for Serve to return")
+		return nil
+	}
+}
+
+// send writes a raw message to the server
+func (p *peer) send(format string, args ...interface{}) {
+	p.t.Helper()
+	if err := p.transport.Write(context.Background(), []byte(fmt.Sprintf(format, args...))); err != nil {
+		p.t.Fatalf("sending message: %v", err)
+	}
+}
+
+// next returns the next message from the server
+func (p *peer) next() message {
+	p.t.Helper()
+	select {
+	case m, ok := <-p.messages:
+		if !ok {
+			p.t.Fatal("server closed the connection")
+		}
+		return m
+	case <-time.After(5 * time.Second):
+		p.t.Fatal("timed out waiting for a message")
+	}
+	return message{}
+}
+
+// quiet fails if the server sends anything within d
+func (p *peer) quiet(d time.Duration) {
+	p.t.Helper()
+	select {
+	case m, ok := <-p.messages:
+		if ok {
+			p.t.Fatalf("unexpected message %+v", m)
+		}
+	case <-time.After(d):
+	}
+}
+
+// initialize completes the initialize handshake
+func (p *peer) initialize() {
+	p.t.Helper()
+	p.send(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":%q,"capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`, types.LatestProtocolVersion)
+	m := p.next()
+	for m.ID == nil {
+		// e.g. a log message from middleware
+		m = p.next()
+	}
+	if m.Error != nil {
+		p.t.Fatalf("initialize: %v", m.Error)
+	}
+	p.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+// rejectVersion makes the server refuse to initialize with version, as a
+// server that only speaks older versions would
+func rejectVersion(version string) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+			if method == types.MethodInitialize {
+				var p types.InitializeParams
+				if err := json.Unmarshal(params, &p); err == nil && p.ProtocolVersion == version {
+					return nil, types.NewUnsupportedProtocolVersionError(version)
+				}
+			}
+			return next.Handle(ctx, method, params)
+		})
+	}
+}
+
+func newServer(t *testing.T) *server.Server {
+	t.Helper()
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func TestInitializeFallsBackToOlderProtocolVersion(t *testing.T) {
+	srv := newServer(t)
+	srv.Use(rejectVersion(types.LatestProtocolVersion))
+	c := connect(t, srv, client.WithProtocolVersionFallback(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := c.Initialize(ctx)
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	want, _ := types.OlderProtocolVersion(types.LatestProtocolVersion)
+	if result.ProtocolVersion != want {
+		t.Errorf("protocol version = %q, want %q", result.ProtocolVersion, want)
+	}
+}
+
+func TestInitializeWithoutFallbackFails(t *testing.T) {
+	srv := newServer(t)
+	srv.Use(rejectVersion(types.LatestProtocolVersion))
+	c := connect(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.Initialize(ctx); !types.IsProtocolVersionError(err) {
+		t.Fatalf("err = %v, want a protocol version error", err)
+	}
+}
+
+func TestListResourcesResolvesAnnotations(t *testing.T) {
+	priority := 0.8
+	resource, err := types.NewResource("file:///report.txt", "report",
+		types.WithResourceAnnotationsFunc(func() (*types.Annotations, error) {
+			return &types.Annotations{Priority: &priority}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := types.NewResourceStore()
+	if err := store.Register(*resource, func() (*types.ReadResourceResult, error) {
+		return &types.ReadResourceResult{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info, server.WithResources(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := srv.Handle(context.Background(), types.MethodListResources, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := res.(*types.ListResourcesResult)
+	if len(result.Resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(result.Resources))
+	}
+	if a := result.Resources[0].Annotations; a == nil || a.Priority == nil || *a.Priority != priority {
+		t.Errorf("annotations = %+v, want priority %v", a, priority)
+	}
+}
+
+func TestCallToolKeepsNumberPrecision(t *testing.T) {
+	tools, err := types.NewToolRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool, err := types.NewTool("pick",
+		types.WithToolProperty("level", types.JSONSchema{Type: types.TypeInteger, Enum: types.SchemaEnum{1, 2, 3}}),
+		types.WithToolProperty("id", types.IntegerSchema),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tools.Register(*tool, types.ToolHandlerFunc(func(ctx context.Context, params types.CallToolParams) (*types.CallToolResult, error) {
+		return types.NewToolResultText(fmt.Sprint(params.Arguments["id"])), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info, server.WithTools(tools))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := json.RawMessage(`{"name":"pick","arguments":{"level":3,"id":9007199254740993}}`)
+	res, err := srv.Handle(context.Background(), types.MethodCallTool, params)
+	if err != nil {
+		t.Fatalf("tools/call: %v", err)
+	}
+	text := res.(*types.CallToolResult).Content[0].TextContent.Text
+	if text != "9007199254740993" {
+		t.Errorf("id reached the handler as %s, want 9007199254740993", text)
+	}
+
+	bad := json.RawMessage(`{"name":"pick","arguments":{"level":4}}`)
+	if _, err := srv.Handle(context.Background(), types.MethodCallTool, bad); err == nil {
+		t.Error("a level outside the enum should be rejected")
+	}
+}
+
+func TestShutdownDrainsRunningRequests(t *testing.T) {
+	srv := newServer(t)
+	started := make(chan struct{})
+	handle(t, srv, "test/work", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		close(started)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			return "finished", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/work"}`)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	m := p.next()
+	var result string
+	if m.Error != nil || json.Unmarshal(m.Result, &result) != nil || result != "finished" {
+		t.Fatalf("got %+v, want the finished response", m)
+	}
+	if err := p.wait(); err != server.ErrServerClosed {
+		t.Errorf("Serve returned %v, want %v", err, server.ErrServerClosed)
+	}
+
+	// the server refuses new sessions once shut down
+	transport, _ := types.NewStdioTransport(strings.NewReader(""), io.Discard)
+	if err := srv.Serve(context.Background(), transport); err != server.ErrServerClosed {
+		t.Errorf("Serve after Shutdown returned %v, want %v", err, server.ErrServerClosed)
+	}
+}
+
+func TestShutdownCancelsRequestsAtDeadline(t *testing.T) {
+	srv := newServer(t)
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	handle(t, srv, "test/stuck", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		cause <- ctx.Err()
+		return "too late", nil
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/stuck"}`)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	m := p.next()
+	if m.Method != types.MethodCancelled {
+		t.Fatalf("got %+v, want a cancelled notification", m)
+	}
+	var params types.CancelledParams
+	if err := json.Unmarshal(m.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.RequestID != types.NewIntRequestID(1) || params.Reason == nil || *params.Reason != "server shutting down" {
+		t.Errorf("cancelled %+v, want request 1 for the shutdown", params)
+	}
+	if err := <-cause; err != context.Canceled {
+		t.Errorf("handler context ended with %v, want %v", err, context.Canceled)
+	}
+
+	// the cancelled request gets no response
+	p.quiet(50 * time.Millisecond)
+	if err := p.wait(); err != server.ErrServerClosed {
+		t.Errorf("Serve returned %v, want %v", err, server.ErrServerClosed)
+	}
+}
@@ -0,0 +1,341 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+type sessionKey struct{}
+
+// SessionFromContext returns the session a request arrived on, or nil if ctx
+// was not passed to a handler by Serve
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// Session is one client connection served by Serve. Handlers reach it through
+// SessionFromContext to send progress, log messages or other notifications to
+// the client that made the request. It is safe for concurrent use.
+type Session struct {
+	server    *Server
+	transport types.Transport
+	emitter   *types.Emitter
+	state     *types.ServerSession
+	logger    *types.Logger
+
+	mu       sync.Mutex
+	closing  bool
+	stop     context.CancelFunc // ends serve; nil until serve starts
+	inflight map[types.RequestID]context.CancelCauseFunc
+	wg       sync.WaitGroup
+}
+
+// Reasons a request context is cancelled without a response being sent
+var (
+	errCancelledByClient = errors.New("cancelled by client")
+	errShutdown          = errors.New("server shutting down")
+)
+
+func newSession(server *Server, transport types.Transport) (*Session, error) {
+	emitter, err := types.NewEmitter(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		server:    server,
+		transport: transport,
+		emitter:   emitter,
+		state:     types.NewServerSession(),
+		inflight:  make(map[types.RequestID]context.CancelCauseFunc),
+	}
+
+	s.logger, err = types.NewLogger(server.logLevel, func(n *types.LoggingMessageNotification) error {
+		return s.emitter.Emit(n)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ProtocolVersion returns the protocol version negotiated during initialize
+func (s *Session) ProtocolVersion() string {
+	return s.state.ProtocolVersion()
+}
+
+// ClientInfo returns the client implementation sent during initialize
+func (s *Session) ClientInfo() types.Implementation {
+	return s.state.ClientInfo()
+}
+
+// ClientCapabilities returns the capabilities the client advertised during initialize
+func (s *Session) ClientCapabilities() types.ClientCapabilities {
+	return s.state.ClientCapabilities()
+}
+
+// Notify sends a notification to this session's client
+func (s *Session) Notify(ctx context.Context, n types.Notification) error {
+	return s.emitter.EmitContext(ctx, n)
+}
+
+// Log sends a log message to the client if level is at or above the level
+// the client set with logging/setLevel
+func (s *Session) Log(level types.LoggingLevel, data interface{}, opts ...types.LoggingMessageOption) error {
+	return s.logger.Emit(level, data, opts...)
+}
+
+// CheckSampling reports whether the client can take a sampling request,
+// rejecting content types it did not advertise before anything is sent
+func (s *Session) CheckSampling(params *types.CreateMessageParams) error {
+	return params.CheckClientSupport(s.ClientCapabilities())
+}
+
+// SlogHandler returns a slog.Handler that sends records to the client as log
+// messages, subject to the level the client set
+func (s *Session) SlogHandler(opts ...types.SlogNotifierOption) (*types.SlogNotifier, error) {
+	return s.logger.SlogHandler(opts...)
+}
+
+// serve reads messages until ctx is done or the transport fails, then cancels
+// and waits for in-flight requests
+func (s *Session) serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	s.mu.Lock()
+	s.stop = cancel
+	closing := s.closing
+	s.mu.Unlock()
+	if closing {
+		return ErrServerClosed
+	}
+
+	type read struct {
+		data json.RawMessage
+		err  error
+	}
+	reads := make(chan read)
+	go func() {
+		for {
+			data, err := s.transport.Read(ctx)
+			select {
+			case reads <- read{data, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-reads:
+			if r.err != nil {
+				return r.err
+			}
+			s.handleMessage(ctx, r.data)
+		}
+	}
+}
+
+// handleMessage routes an incoming message by its shape. Malformed messages are dropped.
+func (s *Session) handleMessage(ctx context.Context, data json.RawMessage) {
+	var probe struct {
+		ID     *types.RequestID `json:"id"`
+		Method string           `json:"method"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return
+	}
+
+	switch {
+	case probe.Method != "" && probe.ID != nil:
+		var req types.JSONRPCRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return
+		}
+		s.startRequest(ctx, req)
+	case probe.Method != "":
+		var n types.JSONRPCNotification
+		if err := json.Unmarshal(data, &n); err != nil {
+			return
+		}
+		s.handleNotification(n)
+	}
+	// responses are dropped: the server sends no requests of its own
+}
+
+// startRequest runs a request on its own goroutine with a context that an
+// incoming cancelled notification can cancel. Once the session is shutting
+// down, requests are refused.
+func (s *Session) startRequest(ctx context.Context, req types.JSONRPCRequest) {
+	reqCtx, cancel := context.WithCancelCause(s.withProgress(context.WithValue(ctx, sessionKey{}, s), req))
+
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		cancel(nil)
+		s.respond(ctx, req.ID, nil, &types.ErrorInfo{
+			Code:    types.ErrInvalidRequest,
+			Message: "Server is shutting down",
+		})
+		return
+	}
+	s.inflight[req.ID] = cancel
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.inflight, req.ID)
+			s.mu.Unlock()
+			cancel(nil)
+		}()
+
+		observer := s.server.observer
+		observer.RequestStarted(req.Method)
+		start := time.Now()
+		result, timedOut, err := s.dispatch(reqCtx, req)
+		if err == nil && reqCtx.Err() != nil {
+			err = context.Cause(reqCtx)
+		}
+		observer.RequestFinished(req.Method, time.Since(start), err)
+		if cause := context.Cause(reqCtx); cause == errCancelledByClient || cause == errShutdown {
+			// the client cancelled the request or was told it was cancelled,
+			// so it expects no response
+			return
+		}
+		var panicked *PanicError
+		if errors.As(err, &panicked) {
+			// the panic value and stack are for the observer, not the client
+			err = &types.ErrorInfo{Code: types.ErrInternal, Message: "Internal error"}
+		}
+		s.respond(ctx, req.ID, result, err)
+		if timedOut {
+			// sent after the response so the client resolves the call with
+			// the timeout error rather than a bare cancellation
+			_ = s.emitter.Emit(types.NewCancelledNotification(req.ID, errTimeout.Error()))
+		}
+	}()
+}
+
+// beginShutdown refuses new requests; those already running continue
+func (s *Session) beginShutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closing = true
+}
+
+// cancelInflight cancels every running request and tells the client with a
+// cancelled notification
+func (s *Session) cancelInflight() {
+	s.mu.Lock()
+	inflight := make(map[types.RequestID]context.CancelCauseFunc, len(s.inflight))
+	for id, cancel := range s.inflight {
+		inflight[id] = cancel
+	}
+	s.mu.Unlock()
+
+	for id, cancel := range inflight {
+		cancel(errShutdown)
+		_ = s.emitter.Emit(types.NewCancelledNotification(id, errShutdown.Error()))
+	}
+}
+
+// close ends serve, which waits for running requests before returning
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closing = true
+	if s.stop != nil {
+		s.stop()
+	}
+}
+
+// dispatch runs the request through the middleware chain under the method's
+// timeout, reporting whether the timeout expired
+func (s *Session) dispatch(ctx context.Context, req types.JSONRPCRequest) (result interface{}, timedOut bool, err error) {
+	// a panicking handler fails its request, not the whole server
+	defer func() {
+		if v := recover(); v != nil {
+			result, timedOut, err = nil, false, &PanicError{Method: req.Method, Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	if err := s.state.CheckRequest(req.Method); err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := s.server.withTimeout(ctx, req.Method)
+	defer cancel()
+
+	result, err = s.server.handler().Handle(ctx, req.Method, req.Params)
+	if context.Cause(ctx) == errTimeout {
+		return nil, true, timeoutError(req, s.server.timeouts[req.Method])
+	}
+	return result, false, err
+}
+
+func (s *Session) handleNotification(n types.JSONRPCNotification) {
+	switch n.Method {
+	case types.MethodInitialized:
+		_ = s.state.HandleInitialized()
+	case types.MethodCancelled:
+		var params types.CancelledParams
+		if err := json.Unmarshal(n.Params, &params); err != nil {
+			return
+		}
+		s.mu.Lock()
+		cancel, ok := s.inflight[params.RequestID]
+		s.mu.Unlock()
+		if ok {
+			cancel(errCancelledByClient)
+		}
+	}
+}
+
+// respond writes the response to a request. Errors other than *ErrorInfo are
+// reported as internal errors.
+func (s *Session) respond(ctx context.Context, id types.RequestID, result interface{}, err error) {
+	resp := types.JSONRPCResponse{JSONRPC: types.JSONRPCVersion, ID: id}
+	if err == nil {
+		resp.Result, err = json.Marshal(result)
+		if err != nil {
+			err = fmt.Errorf("marshaling result: %w", err)
+		}
+	}
+	if err != nil {
+		resp.Result = nil
+		var info *types.ErrorInfo
+		if !errors.As(err, &info) {
+			info = &types.ErrorInfo{Code: types.ErrInternal, Message: err.Error()}
+		}
+		resp.Error = info
+	}
+
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = s.transport.Write(context.WithoutCancel(ctx), msg)
+}
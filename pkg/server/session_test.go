@@ -0,0 +1,193 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// handle routes method to fn on srv
+func handle(t *testing.T, srv *server.Server, method string, fn server.HandlerFunc) {
+	t.Helper()
+	if err := srv.HandleMethod(method, fn); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionRouting(t *testing.T) {
+	srv := newServer(t)
+	notified := make(chan string, 1)
+	handle(t, srv, "test/echo", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return params, nil
+	})
+	handle(t, srv, "test/notified", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		notified <- method
+		return nil, nil
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	tests := []struct {
+		name     string
+		message  string
+		wantID   types.RequestID
+		want     string
+		wantCode int
+	}{
+		{name: "numeric id", message: `{"jsonrpc":"2.0","id":7,"method":"test/echo","params":{"a":1}}`, wantID: types.NewIntRequestID(7), want: `{"a":1}`},
+		{name: "string id", message: `{"jsonrpc":"2.0","id":"x","method":"test/echo","params":[1]}`, wantID: types.NewStringRequestID("x"), want: `[1]`},
+		{name: "built-in method", message: `{"jsonrpc":"2.0","id":8,"method":"ping"}`, wantID: types.NewIntRequestID(8), want: `{}`},
+		{name: "unknown method", message: `{"jsonrpc":"2.0","id":9,"method":"test/missing"}`, wantID: types.NewIntRequestID(9), wantCode: types.ErrMethodNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := p.on(t)
+			p.send(tt.message)
+			m := p.next()
+			if m.ID == nil || *m.ID != tt.wantID {
+				t.Fatalf("got %+v, want the response to %s", m, tt.wantID)
+			}
+			if tt.wantCode != 0 {
+				if m.Error == nil || m.Error.Code != tt.wantCode {
+					t.Errorf("error = %v, want code %d", m.Error, tt.wantCode)
+				}
+				return
+			}
+			if m.Error != nil {
+				t.Fatalf("request failed: %v", m.Error)
+			}
+			if string(m.Result) != tt.want {
+				t.Errorf("result = %s, want %s", m.Result, tt.want)
+			}
+		})
+	}
+
+	t.Run("notification", func(t *testing.T) {
+		p := p.on(t)
+		// notifications never reach request handlers and get no response
+		p.send(`{"jsonrpc":"2.0","method":"test/notified"}`)
+		p.quiet(50 * time.Millisecond)
+		select {
+		case method := <-notified:
+			t.Errorf("notification reached the handler for %s", method)
+		default:
+		}
+	})
+}
+
+func TestSessionCancelledRequestGetsNoResponse(t *testing.T) {
+	srv := newServer(t)
+	cause := make(chan error, 1)
+	handle(t, srv, "test/block", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		cause <- ctx.Err()
+		return "too late", nil
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/block"}`)
+	p.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"user gave up"}}`)
+
+	select {
+	case err := <-cause:
+		if err != context.Canceled {
+			t.Errorf("handler context ended with %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not cancelled")
+	}
+	p.quiet(50 * time.Millisecond)
+
+	// the session keeps serving
+	p.send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if m := p.next(); m.ID == nil || *m.ID != types.NewIntRequestID(2) || m.Error != nil {
+		t.Errorf("got %+v, want the ping response", m)
+	}
+}
+
+func TestSessionRecoversFromPanic(t *testing.T) {
+	srv := newServer(t)
+	handle(t, srv, "test/panic", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		panic("secret detail")
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/panic"}`)
+	m := p.next()
+	if m.Error == nil || m.Error.Code != types.ErrInternal || m.Error.Message != "Internal error" {
+		t.Fatalf("error = %v, want a bare internal error", m.Error)
+	}
+
+	p.send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if m := p.next(); m.Error != nil {
+		t.Errorf("ping after a panic: %v", m.Error)
+	}
+}
+
+func TestSessionRequiresInitialize(t *testing.T) {
+	srv := newServer(t)
+	handle(t, srv, "test/echo", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return "ran", nil
+	})
+	p := dial(t, srv)
+
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/echo"}`)
+	if m := p.next(); m.Error == nil || m.Error.Code != types.ErrServerNotInitialized {
+		t.Errorf("error = %v, want code %d", m.Error, types.ErrServerNotInitialized)
+	}
+
+	// ping is answered at any time
+	p.send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if m := p.next(); m.Error != nil {
+		t.Errorf("ping before initialize: %v", m.Error)
+	}
+
+	p.initialize()
+	p.send(`{"jsonrpc":"2.0","id":3,"method":"test/echo"}`)
+	if m := p.next(); m.Error != nil {
+		t.Errorf("request after initialize: %v", m.Error)
+	}
+}
+
+func TestSessionRefusesRequestsWhileShuttingDown(t *testing.T) {
+	srv := newServer(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	handle(t, srv, "test/block", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		close(started)
+		<-release
+		return "finished", nil
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	// a running request keeps Shutdown draining
+	p.send(`{"jsonrpc":"2.0","id":1,"method":"test/block"}`)
+	<-started
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- srv.Shutdown(context.Background()) }()
+
+	// pings are answered until the session learns of the shutdown
+	var refused *types.ErrorInfo
+	for id := 2; refused == nil && id < 100; id++ {
+		p.send(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id)
+		refused = p.next().Error
+		time.Sleep(time.Millisecond)
+	}
+	if refused == nil || refused.Code != types.ErrInvalidRequest || refused.Message != "Server is shutting down" {
+		t.Fatalf("error = %v, want the shutting down rejection", refused)
+	}
+
+	close(release)
+	if m := p.next(); m.ID == nil || *m.ID != types.NewIntRequestID(1) || m.Error != nil {
+		t.Errorf("got %+v, want the running request's response", m)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// errTimeout is the cause of a request context whose method timeout expired
+var errTimeout = errors.New("request timed out")
+
+// WithMethodTimeouts limits how long requests for each method may run. The
+// handler's context gets the deadline; once it passes, the client receives a
+// tool execution error of type "timeout" followed by a cancelled
+// notification for the request. Handlers are expected to return promptly when
+// their context ends; the response waits for them.
+func WithMethodTimeouts(timeouts map[string]time.Duration) Option {
+	return func(s *Server) error {
+		if s.timeouts == nil {
+			s.timeouts = make(map[string]time.Duration, len(timeouts))
+		}
+		for method, d := range timeouts {
+			if method == "" {
+				return fmt.Errorf("method cannot be empty")
+			}
+			if d <= 0 {
+				return fmt.Errorf("timeout for %s must be positive", method)
+			}
+			s.timeouts[method] = d
+		}
+		return nil
+	}
+}
+
+// withTimeout applies the method's timeout, if any, to ctx
+func (s *Server) withTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	d, ok := s.timeouts[method]
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, d, errTimeout)
+}
+
+// timeoutError reports an expired method timeout. Tool calls name the tool;
+// other methods are reported under their method name.
+func timeoutError(req types.JSONRPCRequest, d time.Duration) *types.ErrorInfo {
+	name := req.Method
+	if req.Method == types.MethodCallTool {
+		var params types.CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err == nil && params.Name != "" {
+			name = params.Name
+		}
+	}
+	return types.NewToolExecutionError(name, "timeout", fmt.Sprintf("%s did not finish within %s", req.Method, d))
+}
@@ -0,0 +1,85 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/artmoskvin/gomcp/pkg/server"
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+func TestMethodTimeout(t *testing.T) {
+	tools, err := types.NewToolRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool, err := types.NewTool("slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tools.Register(*tool, types.ToolHandlerFunc(func(ctx context.Context, params types.CallToolParams) (*types.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := types.NewImplementation("test-server", "1.0.0")
+	srv, err := server.New(*info,
+		server.WithTools(tools),
+		server.WithMethodTimeouts(map[string]time.Duration{
+			types.MethodCallTool: 20 * time.Millisecond,
+			"test/slow":          20 * time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle(t, srv, "test/slow", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	p := dial(t, srv)
+	p.initialize()
+
+	tests := []struct {
+		name     string
+		request  string
+		wantName string
+	}{
+		{name: "tool call", request: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`, wantName: "slow"},
+		{name: "other method", request: `{"jsonrpc":"2.0","id":1,"method":"test/slow"}`, wantName: "test/slow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := p.on(t)
+			p.send(tt.request)
+
+			// the response comes first, so the client resolves the call with
+			// the timeout error rather than a bare cancellation
+			m := p.next()
+			if m.ID == nil || *m.ID != types.NewIntRequestID(1) || m.Error == nil {
+				t.Fatalf("got %+v, want an error response to request 1", m)
+			}
+			data, ok := m.Error.Data.(types.ToolExecutionError)
+			if !ok || data.ErrType != "timeout" || data.ToolName != tt.wantName {
+				t.Errorf("error = %+v, want a timeout for %s", m.Error, tt.wantName)
+			}
+
+			m = p.next()
+			if m.Method != types.MethodCancelled {
+				t.Fatalf("got %+v, want a cancelled notification", m)
+			}
+			var params types.CancelledParams
+			if err := json.Unmarshal(m.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			if params.RequestID != types.NewIntRequestID(1) {
+				t.Errorf("cancelled request %s, want 1", params.RequestID)
+			}
+		})
+	}
+}
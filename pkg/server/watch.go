@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artmoskvin/gomcp/pkg/types"
+)
+
+// WithResourceWatcher serves resources from the watcher's store, as
+// WithResources does, and sends its file change notifications to clients until
+// Shutdown. Updates reach only sessions subscribed to the resource.
+func WithResourceWatcher(watcher *types.FileResourceWatcher) Option {
+	return func(s *Server) error {
+		if watcher == nil {
+			return fmt.Errorf("resource watcher cannot be nil")
+		}
+		s.watcher = watcher
+		return nil
+	}
+}
+
+// startWatcher runs the resource watcher, if any, in the background
+func (s *Server) startWatcher() error {
+	if s.watcher == nil {
+		return nil
+	}
+	if s.resources == nil {
+		s.resources = s.watcher.Store()
+	} else if s.resources != s.watcher.Store() {
+		return fmt.Errorf("resource watcher must use the server's resource store")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatcher = cancel
+	go s.watcher.Run(ctx, s.Notify)
+	return nil
+}
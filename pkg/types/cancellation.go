@@ -0,0 +1,224 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const MethodCancelled = "notifications/cancelled"
+
+// CancelledNotification asks the receiver to stop work on an earlier request
+type CancelledNotification struct {
+	Method string          `json:"method"`
+	Params CancelledParams `json:"params"`
+}
+
+type CancelledParams struct {
+	RequestID RequestID `json:"requestId"`
+	Reason    *string   `json:"reason,omitempty"`
+}
+
+// NewCancelledNotification creates a cancellation for the request with the given ID.
+// An empty reason is omitted.
+func NewCancelledNotification(id RequestID, reason string) *CancelledNotification {
+	n := &CancelledNotification{
+		Method: MethodCancelled,
+		Params: CancelledParams{RequestID: id},
+	}
+	if reason != "" {
+		n.Params.Reason = &reason
+	}
+	return n
+}
+
+// OperationRegistry tracks running progress-reporting operations by request ID
+// so that an incoming CancelledNotification cancels the right one. Progress
+// notifications, including a final one on cancellation, are passed to the
+// sink. It is safe for concurrent use.
+type OperationRegistry struct {
+	mu         sync.Mutex
+	operations map[RequestID]*Operation
+	sink       func(*ProgressNotification) error
+}
+
+func NewOperationRegistry(sink func(*ProgressNotification) error) (*OperationRegistry, error) {
+	if sink == nil {
+		return nil, fmt.Errorf("sink function cannot be nil")
+	}
+
+	return &OperationRegistry{
+		operations: make(map[RequestID]*Operation),
+		sink:       sink,
+	}, nil
+}
+
+// Operation pairs a request's progress token with the cancellation of its context
+type Operation struct {
+	id       RequestID
+	tracker  *ProgressTracker
+	ctx      context.Context
+	cancel   context.CancelFunc
+	registry *OperationRegistry
+
+	mu     sync.Mutex
+	reason *string
+	done   bool
+}
+
+// Start registers an operation for the request and returns it with a context
+// derived from ctx that is cancelled when the request is. Call Finish when the
+// operation ends.
+func (r *OperationRegistry) Start(ctx context.Context, id RequestID, token ProgressToken, opts ...ProgressTrackerOption) (*Operation, error) {
+	tracker, err := NewProgressTracker(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.operations[id]; ok {
+		return nil, fmt.Errorf("operation already registered for request %s", id)
+	}
+
+	opCtx, cancel := context.WithCancel(ctx)
+	op := &Operation{
+		id:       id,
+		tracker:  tracker,
+		ctx:      opCtx,
+		cancel:   cancel,
+		registry: r,
+	}
+	r.operations[id] = op
+	return op, nil
+}
+
+// HandleCancelled cancels the operation the notification refers to. It reports
+// false when no such operation is running, which the spec says to ignore.
+func (r *OperationRegistry) HandleCancelled(n *CancelledNotification) (bool, error) {
+	if n == nil {
+		return false, fmt.Errorf("cancelled notification cannot be nil")
+	}
+
+	r.mu.Lock()
+	op, ok := r.operations[n.Params.RequestID]
+	r.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	reason := ""
+	if n.Params.Reason != nil {
+		reason = *n.Params.Reason
+	}
+	return true, op.Cancel(reason)
+}
+
+func (r *OperationRegistry) remove(id RequestID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.operations, id)
+}
+
+// Context returns the operation's context, done once the operation is cancelled
+func (o *Operation) Context() context.Context {
+	return o.ctx
+}
+
+// Token returns the operation's progress token
+func (o *Operation) Token() ProgressToken {
+	return o.tracker.token
+}
+
+// Report records progress and sends the notification to the registry's sink
+func (o *Operation) Report(progress float64) error {
+	if err := o.ctx.Err(); err != nil {
+		return fmt.Errorf("operation cancelled: %w", err)
+	}
+
+	notification, err := o.tracker.Update(progress)
+	if err != nil {
+		return err
+	}
+	return o.registry.sink(notification)
+}
+
+// Cancel cancels the operation's context, unregisters it and sends a final
+// progress notification at the last reported progress. Cancelling a finished
+// operation does nothing.
+func (o *Operation) Cancel(reason string) error {
+	o.mu.Lock()
+	if o.done {
+		o.mu.Unlock()
+		return nil
+	}
+	o.done = true
+	if reason != "" {
+		o.reason = &reason
+	}
+	o.mu.Unlock()
+
+	o.cancel()
+	o.registry.remove(o.id)
+
+	notification, err := o.tracker.current()
+	if err != nil {
+		return err
+	}
+	return o.registry.sink(notification)
+}
+
+// Reason returns why the operation was cancelled, if a reason was given
+func (o *Operation) Reason() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.reason == nil {
+		return "", false
+	}
+	return *o.reason, true
+}
+
+// Finish unregisters an operation that ended normally and releases its context
+func (o *Operation) Finish() {
+	o.mu.Lock()
+	o.done = true
+	o.mu.Unlock()
+
+	o.cancel()
+	o.registry.remove(o.id)
+}
+
+/* Usage Example:
+func ExampleOperation() {
+    operations, err := NewOperationRegistry(func(n *ProgressNotification) error {
+        return send(n)
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // When a tools/call request with a progress token arrives
+    op, err := operations.Start(ctx, req.ID, token, WithTrackerTotal(100))
+    if err != nil {
+        log.Fatal(err)
+    }
+    go func() {
+        defer op.Finish()
+        for i := 1; i <= 100; i++ {
+            if err := work(op.Context(), i); err != nil {
+                return // cancelled
+            }
+            op.Report(float64(i))
+        }
+    }()
+
+    // When notifications/cancelled arrives
+    var cancelled CancelledNotification
+    json.Unmarshal(data, &cancelled)
+    if _, err := operations.HandleCancelled(&cancelled); err != nil {
+        log.Print(err)
+    }
+}
+*/
@@ -3,6 +3,8 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // ServerCapabilityOption is used to configure ServerCapabilities
@@ -48,7 +50,26 @@ type RootsCapability struct {
 	ListChanged *bool `json:"listChanged,omitempty"`
 }
 
-type SamplingCapability struct{}
+type SamplingCapability struct {
+	// Models lists the model families the client can serve, e.g. "claude-3-5-sonnet"
+	Models []string `json:"models,omitempty"`
+	// Supports lists the content types the client accepts in sampling
+	// messages. Clients that leave it empty accept every type.
+	Supports []ContentType `json:"supports,omitempty"`
+}
+
+// SupportsContentType reports whether the client accepts ct in sampling messages
+func (sc *SamplingCapability) SupportsContentType(ct ContentType) bool {
+	if len(sc.Supports) == 0 {
+		return true
+	}
+	for _, supported := range sc.Supports {
+		if supported == ct {
+			return true
+		}
+	}
+	return false
+}
 
 // Server capabilities constructor and options
 
@@ -94,6 +115,32 @@ func WithServerResources(subscribe, listChanged bool) ServerCapabilityOption {
 	}
 }
 
+// WithServerResourceSubscribe advertises resource subscriptions, keeping any
+// listChanged flag already set
+func WithServerResourceSubscribe() ServerCapabilityOption {
+	return func(sc *ServerCapabilities) error {
+		if sc.Resources == nil {
+			sc.Resources = &ResourcesCapability{}
+		}
+		subscribe := true
+		sc.Resources.Subscribe = &subscribe
+		return nil
+	}
+}
+
+// WithServerResourceListChanged advertises resource list-changed notifications,
+// keeping any subscribe flag already set
+func WithServerResourceListChanged() ServerCapabilityOption {
+	return func(sc *ServerCapabilities) error {
+		if sc.Resources == nil {
+			sc.Resources = &ResourcesCapability{}
+		}
+		listChanged := true
+		sc.Resources.ListChanged = &listChanged
+		return nil
+	}
+}
+
 func WithServerTools(listChanged bool) ServerCapabilityOption {
 	return func(sc *ServerCapabilities) error {
 		sc.Tools = &ToolsCapability{
@@ -114,6 +161,16 @@ func WithServerExperimental(name string, data interface{}) ServerCapabilityOptio
 	}
 }
 
+func WithServerVendorExperimental(vendor, feature string, data interface{}) ServerCapabilityOption {
+	return func(sc *ServerCapabilities) error {
+		key, err := VendorExperimentalKey(vendor, feature)
+		if err != nil {
+			return err
+		}
+		return WithServerExperimental(key, data)(sc)
+	}
+}
+
 // Client capabilities constructor and options
 
 func NewClientCapabilities(opts ...ClientCapabilityOption) (*ClientCapabilities, error) {
@@ -143,7 +200,53 @@ func WithClientRoots(listChanged bool) ClientCapabilityOption {
 
 func WithClientSampling() ClientCapabilityOption {
 	return func(cc *ClientCapabilities) error {
-		cc.Sampling = &SamplingCapability{}
+		if cc.Sampling == nil {
+			cc.Sampling = &SamplingCapability{}
+		}
+		return nil
+	}
+}
+
+// WithClientSamplingModels advertises sampling along with the models the client can serve
+func WithClientSamplingModels(models ...string) ClientCapabilityOption {
+	return func(cc *ClientCapabilities) error {
+		for _, model := range models {
+			if model == "" {
+				return fmt.Errorf("sampling model name cannot be empty")
+			}
+		}
+		if cc.Sampling == nil {
+			cc.Sampling = &SamplingCapability{}
+		}
+		cc.Sampling.Models = append(cc.Sampling.Models, models...)
+		return nil
+	}
+}
+
+// WithClientSamplingContentTypes advertises sampling along with the content
+// types the client accepts in sampling messages, e.g. only ContentTypeText
+func WithClientSamplingContentTypes(contentTypes ...ContentType) ClientCapabilityOption {
+	return func(cc *ClientCapabilities) error {
+		if len(contentTypes) == 0 {
+			return fmt.Errorf("at least one sampling content type is required")
+		}
+		for _, ct := range contentTypes {
+			if ct == "" {
+				return fmt.Errorf("sampling content type cannot be empty")
+			}
+		}
+		if cc.Sampling == nil {
+			cc.Sampling = &SamplingCapability{}
+		}
+	next:
+		for _, ct := range contentTypes {
+			for _, existing := range cc.Sampling.Supports {
+				if existing == ct {
+					continue next
+				}
+			}
+			cc.Sampling.Supports = append(cc.Sampling.Supports, ct)
+		}
 		return nil
 	}
 }
@@ -159,6 +262,111 @@ func WithClientExperimental(name string, data interface{}) ClientCapabilityOptio
 	}
 }
 
+func WithClientVendorExperimental(vendor, feature string, data interface{}) ClientCapabilityOption {
+	return func(cc *ClientCapabilities) error {
+		key, err := VendorExperimentalKey(vendor, feature)
+		if err != nil {
+			return err
+		}
+		return WithClientExperimental(key, data)(cc)
+	}
+}
+
+// WithClientSchemaDialect declares the newest JSON Schema dialect the client
+// understands, so that servers can Downgrade tool schemas for it
+func WithClientSchemaDialect(dialect string) ClientCapabilityOption {
+	return func(cc *ClientCapabilities) error {
+		if _, ok := schemaDialectRank(dialect); !ok {
+			return fmt.Errorf("unknown schema dialect: %s", dialect)
+		}
+		return WithClientExperimental(ExperimentalSchemaDialect, dialect)(cc)
+	}
+}
+
+// VendorExperimentalKey namespaces an experimental capability as "vendor/feature"
+// to avoid collisions between vendors
+func VendorExperimentalKey(vendor, feature string) (string, error) {
+	if vendor == "" || feature == "" {
+		return "", fmt.Errorf("vendor and feature cannot be empty")
+	}
+	if strings.Contains(vendor, "/") {
+		return "", fmt.Errorf("vendor cannot contain '/': %s", vendor)
+	}
+	return vendor + "/" + feature, nil
+}
+
+// ListExperimental returns the experimental capability keys in sorted order
+func ListExperimental(experimental map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(experimental))
+	for key := range experimental {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Server capability predicates
+
+func (sc ServerCapabilities) HasLogging() bool {
+	return sc.Logging != nil
+}
+
+func (sc ServerCapabilities) HasPrompts() bool {
+	return sc.Prompts != nil
+}
+
+func (sc ServerCapabilities) HasPromptListChanged() bool {
+	return sc.Prompts != nil && isTrue(sc.Prompts.ListChanged)
+}
+
+func (sc ServerCapabilities) HasResources() bool {
+	return sc.Resources != nil
+}
+
+func (sc ServerCapabilities) HasResourceSubscribe() bool {
+	return sc.Resources != nil && isTrue(sc.Resources.Subscribe)
+}
+
+func (sc ServerCapabilities) HasResourceListChanged() bool {
+	return sc.Resources != nil && isTrue(sc.Resources.ListChanged)
+}
+
+func (sc ServerCapabilities) HasTools() bool {
+	return sc.Tools != nil
+}
+
+func (sc ServerCapabilities) HasToolListChanged() bool {
+	return sc.Tools != nil && isTrue(sc.Tools.ListChanged)
+}
+
+func (sc ServerCapabilities) HasExperimental(name string) bool {
+	_, ok := sc.Experimental[name]
+	return ok
+}
+
+// Client capability predicates
+
+func (cc ClientCapabilities) HasRoots() bool {
+	return cc.Roots != nil
+}
+
+func (cc ClientCapabilities) HasRootListChanged() bool {
+	return cc.Roots != nil && isTrue(cc.Roots.ListChanged)
+}
+
+func (cc ClientCapabilities) HasSampling() bool {
+	return cc.Sampling != nil
+}
+
+func (cc ClientCapabilities) HasExperimental(name string) bool {
+	_, ok := cc.Experimental[name]
+	return ok
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
 /* Usage Example:
 func ExampleCapabilities() {
     // Create server capabilities
@@ -178,16 +386,41 @@ func ExampleCapabilities() {
         log.Fatal(err)
     }
 
+    // Subscriptions without list-changed notifications
+    subscribeOnly, err := NewServerCapabilities(
+        WithServerResourceSubscribe(),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Create client capabilities
     clientCaps, err := NewClientCapabilities(
         WithClientRoots(true),  // with list changes
         WithClientSampling(),
+        WithClientSamplingModels("claude-3-5-sonnet", "claude-3-haiku"),
+        WithClientSamplingContentTypes(ContentTypeText), // text-only client
         WithClientExperimental("customFeature", map[string]interface{}{
             "enabled": true,
         }),
+        WithClientVendorExperimental("acme", "streaming", map[string]interface{}{
+            "enabled": true,
+        }),
     )
     if err != nil {
         log.Fatal(err)
     }
+
+    // ["acme/streaming", "customFeature"]
+    keys := ListExperimental(clientCaps.Experimental)
+
+    // Feature-gate on negotiated capabilities without nil checks
+    if serverCaps.HasResourceSubscribe() {
+        // safe to send resources/subscribe
+    }
+    if clientCaps.HasSampling() {
+        // safe to send sampling/createMessage, using
+        // clientCaps.Sampling.Models to pick realistic model hints
+    }
 }
 */
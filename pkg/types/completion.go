@@ -1,7 +1,11 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // CompleteRequestOption configures CompleteRequest
@@ -14,10 +18,22 @@ type CompleteRequest struct {
 }
 
 type CompleteParams struct {
-	Ref      Reference     `json:"ref"`
-	Argument CompletionArg `json:"argument"`
+	Ref      Reference          `json:"ref"`
+	Argument CompletionArg      `json:"argument"`
+	Context  *CompletionContext `json:"context,omitempty"`
 }
 
+// CompletionContext carries arguments the user has already filled in
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// Reference types
+const (
+	RefTypePrompt   = "ref/prompt"
+	RefTypeResource = "ref/resource"
+)
+
 // Reference represents either a prompt or resource reference
 type Reference struct {
 	Type string `json:"type"`
@@ -28,18 +44,43 @@ type Reference struct {
 
 func NewPromptReference(name string) Reference {
 	return Reference{
-		Type: "ref/prompt",
+		Type: RefTypePrompt,
 		Name: &name,
 	}
 }
 
 func NewResourceReference(uri string) Reference {
 	return Reference{
-		Type: "ref/resource",
+		Type: RefTypeResource,
 		URI:  &uri,
 	}
 }
 
+// Identifier returns the prompt name for prompt references and the URI for
+// resource references
+func (r Reference) Identifier() (string, error) {
+	if err := validateReference(r); err != nil {
+		return "", err
+	}
+	if r.Type == RefTypePrompt {
+		return *r.Name, nil
+	}
+	return *r.URI, nil
+}
+
+// Equal reports whether both references have the same type and point to the
+// same prompt name or resource URI
+func (r Reference) Equal(other Reference) bool {
+	return r.Type == other.Type && equalStringPtr(r.Name, other.Name) && equalStringPtr(r.URI, other.URI)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 type CompletionArg struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -55,7 +96,7 @@ func NewCompleteRequest(ref Reference, argName, argValue string, opts ...Complet
 	}
 
 	req := &CompleteRequest{
-		Method: "completion/complete",
+		Method: MethodComplete,
 		Params: CompleteParams{
 			Ref: ref,
 			Argument: CompletionArg{
@@ -74,16 +115,68 @@ func NewCompleteRequest(ref Reference, argName, argValue string, opts ...Complet
 	return req, nil
 }
 
+// IsPromptRef reports whether the request completes a prompt argument
+func (r *CompleteRequest) IsPromptRef() bool {
+	return r.Params.Ref.Type == RefTypePrompt
+}
+
+// IsResourceRef reports whether the request completes a resource template variable
+func (r *CompleteRequest) IsResourceRef() bool {
+	return r.Params.Ref.Type == RefTypeResource
+}
+
+// Target returns the referenced prompt name or resource URI, or an error if the
+// reference is malformed
+func (r *CompleteRequest) Target() (string, error) {
+	return r.Params.Ref.Identifier()
+}
+
+// CompletionCacheKey returns a key identifying the completion a request asks
+// for: its reference, argument and context arguments, encoded as canonical JSON.
+// Requests differing only in Method or in nil versus empty context share a key.
+func CompletionCacheKey(req CompleteRequest) string {
+	var args map[string]string
+	if req.Params.Context != nil && len(req.Params.Context.Arguments) > 0 {
+		args = req.Params.Context.Arguments
+	}
+
+	// map keys are sorted by encoding/json, so equal requests encode identically
+	key, err := json.Marshal(struct {
+		Ref      Reference         `json:"ref"`
+		Argument CompletionArg     `json:"argument"`
+		Context  map[string]string `json:"context,omitempty"`
+	}{
+		Ref:      req.Params.Ref,
+		Argument: req.Params.Argument,
+		Context:  args,
+	})
+	if err != nil {
+		// unreachable: every field is a string or a map of strings
+		panic(fmt.Sprintf("encoding completion cache key: %v", err))
+	}
+	return string(key)
+}
+
+// CompleteRequest options
+
+// WithCompletionContext passes already-resolved arguments to the completion source
+func WithCompletionContext(arguments map[string]string) CompleteRequestOption {
+	return func(r *CompleteRequest) error {
+		r.Params.Context = &CompletionContext{Arguments: arguments}
+		return nil
+	}
+}
+
 func validateReference(ref Reference) error {
 	switch ref.Type {
-	case "ref/prompt":
+	case RefTypePrompt:
 		if ref.Name == nil || *ref.Name == "" {
 			return fmt.Errorf("prompt reference requires name")
 		}
 		if ref.URI != nil {
 			return fmt.Errorf("prompt reference should not have URI")
 		}
-	case "ref/resource":
+	case RefTypeResource:
 		if ref.URI == nil || *ref.URI == "" {
 			return fmt.Errorf("resource reference requires URI")
 		}
@@ -102,6 +195,9 @@ type CompleteResultOption func(*CompleteResult) error
 // CompleteResult represents the response to a completion request
 type CompleteResult struct {
 	Completion CompletionInfo `json:"completion"`
+
+	maxValues int
+	foldCase  bool
 }
 
 type CompletionInfo struct {
@@ -110,15 +206,120 @@ type CompletionInfo struct {
 	HasMore *bool    `json:"hasMore,omitempty"`
 }
 
+// MaxCompletionValues is the spec's cap on values in a single completion
+// result, applied by NewCompleteResult unless WithMaxValues overrides it
+const MaxCompletionValues = 100
+
+// NewCompleteResult builds a completion result. Values beyond the cap are
+// dropped and HasMore is set so the client knows to narrow its query.
 func NewCompleteResult(values []string, opts ...CompleteResultOption) (*CompleteResult, error) {
-	if len(values) > 100 {
-		return nil, fmt.Errorf("completion values cannot exceed 100 items")
+	result := &CompleteResult{
+		Completion: CompletionInfo{
+			Values: values,
+		},
+		maxValues: MaxCompletionValues,
+	}
+
+	for _, opt := range opts {
+		if err := opt(result); err != nil {
+			return nil, fmt.Errorf("applying complete result option: %w", err)
+		}
+	}
+	if err := checkResultTotal(result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Completion.Values) > result.maxValues {
+		result.Completion.Values = result.Completion.Values[:result.maxValues]
+		hasMore := true
+		result.Completion.HasMore = &hasMore
+	}
+
+	return result, nil
+}
+
+// NewCompleteResultSorted builds a result from values gathered from several
+// sources: duplicates are dropped and the rest sorted before the cap is applied.
+// Options see the deduplicated values, so WithResultTotal is checked against
+// the distinct count. Unless WithResultTotal is given, Total is the number of
+// distinct values. HasMore is set only when a total was given or values were
+// cut off by the cap, and reports whether Total exceeds the values returned.
+// values is not modified.
+func NewCompleteResultSorted(values []string, opts ...CompleteResultOption) (*CompleteResult, error) {
+	result := &CompleteResult{
+		Completion: CompletionInfo{
+			Values: dedupeSorted(values, false),
+		},
+		maxValues: MaxCompletionValues,
+	}
+
+	for _, opt := range opts {
+		if err := opt(result); err != nil {
+			return nil, fmt.Errorf("applying complete result option: %w", err)
+		}
+	}
+
+	if result.foldCase {
+		result.Completion.Values = dedupeSorted(result.Completion.Values, true)
 	}
+	given := result.Completion.Total != nil
+	if !given {
+		total := len(result.Completion.Values)
+		result.Completion.Total = &total
+	}
+	if err := checkResultTotal(result); err != nil {
+		return nil, err
+	}
+
+	truncated := len(result.Completion.Values) > result.maxValues
+	if truncated {
+		result.Completion.Values = result.Completion.Values[:result.maxValues]
+	}
+	if given || truncated {
+		hasMore := *result.Completion.Total > len(result.Completion.Values)
+		result.Completion.HasMore = &hasMore
+	}
+
+	return result, nil
+}
+
+// dedupeSorted returns a sorted copy of values without duplicates. With
+// foldCase, values equal under case folding are duplicates and sort together;
+// the first in byte order is kept.
+func dedupeSorted(values []string, foldCase bool) []string {
+	sorted := append([]string(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if foldCase {
+			if a, b := strings.ToLower(sorted[i]), strings.ToLower(sorted[j]); a != b {
+				return a < b
+			}
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	out := sorted[:0]
+	for i, v := range sorted {
+		if i > 0 {
+			prev := out[len(out)-1]
+			if v == prev || (foldCase && strings.EqualFold(v, prev)) {
+				continue
+			}
+		}
+		out = append(out, v)
+	}
+	return out
+}
 
+// NewCompleteResultPaged builds a result for a source that knows its full match
+// count: values are capped to MaxCompletionValues, or the WithMaxValues limit,
+// Total is set to total and HasMore reports whether total exceeds the values
+// returned
+func NewCompleteResultPaged(values []string, total int, opts ...CompleteResultOption) (*CompleteResult, error) {
 	result := &CompleteResult{
 		Completion: CompletionInfo{
 			Values: values,
 		},
+		maxValues: MaxCompletionValues,
 	}
 
 	for _, opt := range opts {
@@ -127,21 +328,63 @@ func NewCompleteResult(values []string, opts ...CompleteResultOption) (*Complete
 		}
 	}
 
+	if len(result.Completion.Values) > result.maxValues {
+		result.Completion.Values = result.Completion.Values[:result.maxValues]
+	}
+	if total < len(result.Completion.Values) {
+		return nil, fmt.Errorf("total (%d) cannot be less than number of values (%d)", total, len(result.Completion.Values))
+	}
+	result.Completion.Total = &total
+	hasMore := total > len(result.Completion.Values)
+	result.Completion.HasMore = &hasMore
+
 	return result, nil
 }
 
 // CompleteResult options
 
+// WithMaxValues overrides MaxCompletionValues, for clients with a smaller
+// display budget or peers that allow more values
+func WithMaxValues(n int) CompleteResultOption {
+	return func(r *CompleteResult) error {
+		if n <= 0 {
+			return fmt.Errorf("max values must be positive")
+		}
+		r.maxValues = n
+		return nil
+	}
+}
+
+// WithCaseInsensitiveDedup makes NewCompleteResultSorted treat values that
+// differ only in case as duplicates. Other constructors ignore it.
+func WithCaseInsensitiveDedup() CompleteResultOption {
+	return func(r *CompleteResult) error {
+		r.foldCase = true
+		return nil
+	}
+}
+
+// WithResultTotal sets the number of matches there are in all. The
+// constructor rejects a total below the number of values it was given.
 func WithResultTotal(total int) CompleteResultOption {
 	return func(r *CompleteResult) error {
-		if total < len(r.Completion.Values) {
-			return fmt.Errorf("total (%d) cannot be less than number of values (%d)", total, len(r.Completion.Values))
+		if total < 0 {
+			return fmt.Errorf("total cannot be negative")
 		}
 		r.Completion.Total = &total
 		return nil
 	}
 }
 
+// checkResultTotal fails if the result's total is below its number of values,
+// before the cap is applied
+func checkResultTotal(r *CompleteResult) error {
+	if r.Completion.Total != nil && *r.Completion.Total < len(r.Completion.Values) {
+		return fmt.Errorf("total (%d) cannot be less than number of values (%d)", *r.Completion.Total, len(r.Completion.Values))
+	}
+	return nil
+}
+
 func WithHasMore(hasMore bool) CompleteResultOption {
 	return func(r *CompleteResult) error {
 		r.Completion.HasMore = &hasMore
@@ -149,6 +392,149 @@ func WithHasMore(hasMore bool) CompleteResultOption {
 	}
 }
 
+// CompletionProvider produces completion values for one prompt or resource template.
+// ctx holds arguments the user has already filled in and may be nil.
+type CompletionProvider interface {
+	Complete(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error)
+}
+
+// CompletionProviderFunc adapts a function to the CompletionProvider interface
+type CompletionProviderFunc func(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error)
+
+func (f CompletionProviderFunc) Complete(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error) {
+	return f(ref, arg, ctx)
+}
+
+// StaticCompletions returns a provider completing from a fixed list of values,
+// keeping those that start with the typed value, in order
+func StaticCompletions(values ...string) CompletionProvider {
+	return CompletionProviderFunc(func(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error) {
+		matches := make([]string, 0, len(values))
+		for _, v := range values {
+			if strings.HasPrefix(v, arg.Value) {
+				matches = append(matches, v)
+			}
+		}
+		return NewCompleteResultPaged(matches, len(matches))
+	})
+}
+
+// CompletionRegistry routes completion/complete requests to providers registered
+// by prompt name or resource URI template. It is safe for concurrent use.
+type CompletionRegistry struct {
+	mu        sync.RWMutex
+	prompts   map[string]CompletionProvider
+	resources map[string]CompletionProvider
+}
+
+func NewCompletionRegistry() *CompletionRegistry {
+	return &CompletionRegistry{
+		prompts:   make(map[string]CompletionProvider),
+		resources: make(map[string]CompletionProvider),
+	}
+}
+
+// RegisterPrompt sets the provider for a prompt's arguments
+func (r *CompletionRegistry) RegisterPrompt(name string, provider CompletionProvider) error {
+	if name == "" {
+		return fmt.Errorf("prompt name cannot be empty")
+	}
+	if provider == nil {
+		return fmt.Errorf("completion provider cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts[name] = provider
+	return nil
+}
+
+// RegisterResourceTemplate sets the provider for a resource template's variables
+func (r *CompletionRegistry) RegisterResourceTemplate(uriTemplate string, provider CompletionProvider) error {
+	if uriTemplate == "" {
+		return fmt.Errorf("URI template cannot be empty")
+	}
+	if provider == nil {
+		return fmt.Errorf("completion provider cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resources[uriTemplate] = provider
+	return nil
+}
+
+// RegisterTemplateCompletions registers the variable completion sources set on
+// the template with WithTemplateCompletion. Requests for a resource reference to
+// the template are routed to the source for the requested variable.
+func (r *CompletionRegistry) RegisterTemplateCompletions(template *ResourceTemplate) error {
+	if template == nil {
+		return fmt.Errorf("resource template cannot be nil")
+	}
+	if len(template.completions) == 0 {
+		return fmt.Errorf("template %s has no completion sources", template.Name)
+	}
+
+	sources := make(map[string]CompletionProvider, len(template.completions))
+	for variable, provider := range template.completions {
+		sources[variable] = provider
+	}
+
+	return r.RegisterResourceTemplate(template.URITemplate, CompletionProviderFunc(
+		func(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error) {
+			provider, ok := sources[arg.Name]
+			if !ok {
+				return nil, &ErrorInfo{
+					Code:    ErrInvalidParams,
+					Message: fmt.Sprintf("No completion source for variable %s", arg.Name),
+				}
+			}
+			return provider.Complete(ref, arg, ctx)
+		},
+	))
+}
+
+// Complete handles a completion/complete request by calling the matching provider.
+// Unknown references yield an invalid params error.
+func (r *CompletionRegistry) Complete(req *CompleteRequest) (*CompleteResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("complete request cannot be nil")
+	}
+
+	ref := req.Params.Ref
+	id, err := ref.Identifier()
+	if err != nil {
+		return nil, &ErrorInfo{
+			Code:    ErrInvalidParams,
+			Message: fmt.Sprintf("Invalid reference: %v", err),
+		}
+	}
+
+	r.mu.RLock()
+	var provider CompletionProvider
+	var ok bool
+	if ref.Type == RefTypePrompt {
+		provider, ok = r.prompts[id]
+	} else {
+		provider, ok = r.resources[id]
+	}
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, &ErrorInfo{
+			Code:    ErrInvalidParams,
+			Message: "No completion source for reference",
+		}
+	}
+
+	var ctx map[string]string
+	if req.Params.Context != nil {
+		ctx = req.Params.Context.Arguments
+	}
+
+	return provider.Complete(ref, req.Params.Argument, ctx)
+}
+
 /* Usage Example:
 func ExampleCompletion() {
     // Create a completion request for a prompt argument
@@ -187,6 +573,28 @@ func ExampleCompletion() {
         log.Fatal(err)
     }
 
+    // A client that shows five suggestions at a time; HasMore is set if
+    // matches had more than five values
+    shortResult, err := NewCompleteResult(matches, WithMaxValues(5))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Values merged from several lookups, deduplicated and sorted
+    mergedResult, err := NewCompleteResultSorted(
+        append(recentFiles, indexedFiles...),
+        WithCaseInsensitiveDedup(),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // A source with thousands of matches returns the first page and the full count
+    pagedResult, err := NewCompleteResultPaged(matches, len(matches), WithMaxValues(20))
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Example completion result for environments
     envResult, err := NewCompleteResult(
         []string{
@@ -234,6 +642,39 @@ func ExampleCompletion() {
     }
 }
 
+// Example of serving completions through a registry
+func ExampleCompletionRegistry() {
+    registry := NewCompletionRegistry()
+
+    err := registry.RegisterPrompt("generateCode", CompletionProviderFunc(
+        func(ref Reference, arg CompletionArg, ctx map[string]string) (*CompleteResult, error) {
+            if arg.Name != "language" {
+                return NewCompleteResult(nil)
+            }
+            var values []string
+            for _, lang := range []string{"go", "python", "rust"} {
+                if strings.HasPrefix(lang, arg.Value) {
+                    values = append(values, lang)
+                }
+            }
+            return NewCompleteResult(values)
+        },
+    ))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    request, _ := NewCompleteRequest(NewPromptReference("generateCode"), "language", "py")
+    result, err := registry.Complete(request) // ["python"]
+
+    // Cache expensive completions by request identity
+    key := CompletionCacheKey(*request)
+    if cached, ok := cache[key]; ok {
+        return cached
+    }
+    cache[key] = result
+}
+
 // Example of structured completions
 func ExampleStructuredCompletions() {
     // Configuration completion
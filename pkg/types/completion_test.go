@@ -0,0 +1,82 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewCompleteResultPaged(t *testing.T) {
+	values := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		name    string
+		total   int
+		opts    []CompleteResultOption
+		want    int
+		hasMore bool
+	}{
+		{"all values", 4, nil, 4, false},
+		{"more than returned", 40, nil, 4, true},
+		{"capped by max values", 4, []CompleteResultOption{WithMaxValues(2)}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCompleteResultPaged(values, tt.total, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(result.Completion.Values); got != tt.want {
+				t.Errorf("%d values, want %d", got, tt.want)
+			}
+			if *result.Completion.Total != tt.total {
+				t.Errorf("total = %d, want %d", *result.Completion.Total, tt.total)
+			}
+			if *result.Completion.HasMore != tt.hasMore {
+				t.Errorf("hasMore = %v, want %v", *result.Completion.HasMore, tt.hasMore)
+			}
+		})
+	}
+
+	if _, err := NewCompleteResultPaged(values, 3); err == nil {
+		t.Error("expected an error for a total below the number of values")
+	}
+}
+
+func TestNewCompleteResultSorted(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name    string
+		values  []string
+		opts    []CompleteResultOption
+		want    []string
+		total   int
+		hasMore *bool
+	}{
+		{"deduplicated and sorted", []string{"b", "a", "b"}, nil, []string{"a", "b"}, 2, nil},
+		{"total after case-insensitive dedup", []string{"a", "A", "b"}, []CompleteResultOption{WithCaseInsensitiveDedup(), WithResultTotal(2)}, []string{"A", "b"}, 2, new(bool)},
+		{"total above the values", []string{"a", "b"}, []CompleteResultOption{WithResultTotal(5)}, []string{"a", "b"}, 5, &yes},
+		{"capped by max values", []string{"c", "b", "a"}, []CompleteResultOption{WithMaxValues(2)}, []string{"a", "b"}, 3, &yes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCompleteResultSorted(tt.values, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Completion.Values, tt.want) {
+				t.Errorf("values = %v, want %v", result.Completion.Values, tt.want)
+			}
+			if *result.Completion.Total != tt.total {
+				t.Errorf("total = %d, want %d", *result.Completion.Total, tt.total)
+			}
+			if !reflect.DeepEqual(result.Completion.HasMore, tt.hasMore) {
+				t.Errorf("hasMore = %v, want %v", result.Completion.HasMore, tt.hasMore)
+			}
+		})
+	}
+
+	if _, err := NewCompleteResultSorted([]string{"a", "A", "b"}, WithCaseInsensitiveDedup(), WithResultTotal(1)); err == nil {
+		t.Error("expected an error for a total below the number of distinct values")
+	}
+}
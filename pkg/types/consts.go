@@ -4,3 +4,9 @@ const (
 	LatestProtocolVersion = "2024-11-05"
 	JSONRPCVersion        = "2.0"
 )
+
+// SupportedProtocolVersions lists the protocol versions this package can speak, newest first
+var SupportedProtocolVersions = []string{
+	LatestProtocolVersion,
+	"2024-10-07",
+}
@@ -3,6 +3,10 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
 )
 
 type Role string
@@ -17,6 +21,22 @@ type Annotations struct {
 	// TODO: check how this relates to Message.Role
 	Audience []Role   `json:"audience,omitempty"`
 	Priority *float64 `json:"priority,omitempty"`
+	// Source is the URI of the upstream source the content came from
+	Source *string `json:"source,omitempty"`
+}
+
+// IsEmpty reports whether the annotations carry no information. Marshalers
+// omit empty annotations rather than emitting "annotations":{}.
+func (a *Annotations) IsEmpty() bool {
+	return a == nil || (len(a.Audience) == 0 && a.Priority == nil && a.Source == nil)
+}
+
+// nonEmptyAnnotations returns a, or nil if a is empty
+func nonEmptyAnnotations(a *Annotations) *Annotations {
+	if a.IsEmpty() {
+		return nil
+	}
+	return a
 }
 
 func (a *Annotations) Validate() error {
@@ -41,6 +61,67 @@ func (a *Annotations) Validate() error {
 		}
 	}
 
+	if a.Source != nil {
+		if err := validateSourceURI(*a.Source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clamp corrects annotations in place instead of rejecting them: priority is
+// clamped into 0-1 and unknown audience roles are dropped. It returns the number
+// of corrections made. Source is left untouched.
+func (a *Annotations) Clamp() int {
+	if a == nil {
+		return 0
+	}
+
+	corrections := 0
+	if a.Priority != nil {
+		p := *a.Priority
+		switch {
+		case math.IsNaN(p):
+			a.Priority = nil
+			corrections++
+		case p < 0:
+			p = 0
+			a.Priority = &p
+			corrections++
+		case p > 1:
+			p = 1
+			a.Priority = &p
+			corrections++
+		}
+	}
+
+	if len(a.Audience) > 0 {
+		audience := make([]Role, 0, len(a.Audience))
+		for _, role := range a.Audience {
+			switch role {
+			case RoleUser, RoleAssistant:
+				audience = append(audience, role)
+			default:
+				corrections++
+			}
+		}
+		if len(audience) != len(a.Audience) {
+			a.Audience = audience
+		}
+	}
+
+	return corrections
+}
+
+func validateSourceURI(source string) error {
+	u, err := url.Parse(source)
+	if err != nil {
+		return fmt.Errorf("invalid source URI: %w", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("source must be an absolute URI, got %q", source)
+	}
 	return nil
 }
 
@@ -50,6 +131,7 @@ type ContentType string
 const (
 	ContentTypeText     ContentType = "text"
 	ContentTypeImage    ContentType = "image"
+	ContentTypeAudio    ContentType = "audio"
 	ContentTypeResource ContentType = "resource"
 )
 
@@ -60,7 +142,49 @@ type Content struct {
 	// Only one of these will be non-nil
 	TextContent     *TextContent     `json:"text,omitempty"`
 	ImageContent    *ImageContent    `json:"image,omitempty"`
+	AudioContent    *AudioContent    `json:"audio,omitempty"`
 	ResourceContent *ResourceContent `json:"resource,omitempty"`
+	// CustomContent holds the value of a type added with RegisterContentType
+	CustomContent interface{} `json:"-"`
+}
+
+var (
+	customContentMu    sync.RWMutex
+	customContentTypes = map[ContentType]func() interface{}{}
+)
+
+// RegisterContentType makes Content able to decode an additional content type.
+// The factory must return a pointer to a fresh value to unmarshal into; the
+// decoded value is stored in Content.CustomContent. Built-in types cannot be
+// replaced.
+func RegisterContentType(ct ContentType, factory func() interface{}) error {
+	if ct == "" {
+		return fmt.Errorf("content type cannot be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("content factory cannot be nil")
+	}
+
+	switch ct {
+	case ContentTypeText, ContentTypeImage, ContentTypeAudio, ContentTypeResource:
+		return fmt.Errorf("cannot override built-in content type: %s", ct)
+	}
+
+	customContentMu.Lock()
+	defer customContentMu.Unlock()
+
+	if _, ok := customContentTypes[ct]; ok {
+		return fmt.Errorf("content type already registered: %s", ct)
+	}
+	customContentTypes[ct] = factory
+	return nil
+}
+
+func customContentFactory(ct ContentType) (func() interface{}, bool) {
+	customContentMu.RLock()
+	defer customContentMu.RUnlock()
+	factory, ok := customContentTypes[ct]
+	return factory, ok
 }
 
 type TextContent struct {
@@ -74,6 +198,65 @@ type ImageContent struct {
 	Annotations *Annotations `json:"annotations,omitempty"`
 }
 
+type AudioContent struct {
+	Data        string       `json:"data"` // base64 encoded
+	MimeType    string       `json:"mimeType"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+}
+
+// Validate checks that the populated field matches Type, that image and audio
+// MIME types match their content kind, and that annotations are valid
+func (c *Content) Validate() error {
+	switch c.Type {
+	case ContentTypeText:
+		if c.TextContent == nil {
+			return fmt.Errorf("text content is nil")
+		}
+		return c.TextContent.Annotations.Validate()
+	case ContentTypeImage:
+		if c.ImageContent == nil {
+			return fmt.Errorf("image content is nil")
+		}
+		return c.ImageContent.Validate()
+	case ContentTypeAudio:
+		if c.AudioContent == nil {
+			return fmt.Errorf("audio content is nil")
+		}
+		return c.AudioContent.Validate()
+	case ContentTypeResource:
+		if c.ResourceContent == nil {
+			return fmt.Errorf("resource content is nil")
+		}
+		return c.ResourceContent.Annotations.Validate()
+	default:
+		if _, ok := customContentFactory(c.Type); !ok {
+			return fmt.Errorf("unknown content type: %s", c.Type)
+		}
+		return nil
+	}
+}
+
+func (i *ImageContent) Validate() error {
+	if err := validateMimePrefix(i.MimeType, "image/"); err != nil {
+		return err
+	}
+	return i.Annotations.Validate()
+}
+
+func (a *AudioContent) Validate() error {
+	if err := validateMimePrefix(a.MimeType, "audio/"); err != nil {
+		return err
+	}
+	return a.Annotations.Validate()
+}
+
+func validateMimePrefix(mimeType, prefix string) error {
+	if !strings.HasPrefix(strings.ToLower(mimeType), prefix) {
+		return fmt.Errorf("mime type must start with %s, got %q", prefix, mimeType)
+	}
+	return nil
+}
+
 // Custom JSON marshaling/unmarshaling
 func (c *Content) UnmarshalJSON(data []byte) error {
 	// First unmarshal the discriminator
@@ -101,6 +284,13 @@ func (c *Content) UnmarshalJSON(data []byte) error {
 		}
 		c.Type = ContentTypeImage
 		c.ImageContent = &img
+	case ContentTypeAudio:
+		var audio AudioContent
+		if err := json.Unmarshal(data, &audio); err != nil {
+			return err
+		}
+		c.Type = ContentTypeAudio
+		c.AudioContent = &audio
 	case ContentTypeResource:
 		var res ResourceContent
 		if err := json.Unmarshal(data, &res); err != nil {
@@ -109,7 +299,16 @@ func (c *Content) UnmarshalJSON(data []byte) error {
 		c.Type = ContentTypeResource
 		c.ResourceContent = &res
 	default:
-		return fmt.Errorf("unknown content type: %s", t.Type)
+		factory, ok := customContentFactory(t.Type)
+		if !ok {
+			return fmt.Errorf("unknown content type: %s", t.Type)
+		}
+		custom := factory()
+		if err := json.Unmarshal(data, custom); err != nil {
+			return err
+		}
+		c.Type = t.Type
+		c.CustomContent = custom
 	}
 
 	return nil
@@ -121,38 +320,81 @@ func (c Content) MarshalJSON() ([]byte, error) {
 		if c.TextContent == nil {
 			return nil, fmt.Errorf("text content is nil")
 		}
+		text := *c.TextContent
+		text.Annotations = nonEmptyAnnotations(text.Annotations)
 		return json.Marshal(struct {
 			Type ContentType `json:"type"`
 			*TextContent
 		}{
 			Type:        ContentTypeText,
-			TextContent: c.TextContent,
+			TextContent: &text,
 		})
 	case ContentTypeImage:
 		if c.ImageContent == nil {
 			return nil, fmt.Errorf("image content is nil")
 		}
+		img := *c.ImageContent
+		img.Annotations = nonEmptyAnnotations(img.Annotations)
 		return json.Marshal(struct {
 			Type ContentType `json:"type"`
 			*ImageContent
 		}{
 			Type:         ContentTypeImage,
-			ImageContent: c.ImageContent,
+			ImageContent: &img,
 		})
-	case ContentTypeResource:
-		if c.ResourceContent == nil {
-			return nil, fmt.Errorf("resource content is nil")
+	case ContentTypeAudio:
+		if c.AudioContent == nil {
+			return nil, fmt.Errorf("audio content is nil")
 		}
+		audio := *c.AudioContent
+		audio.Annotations = nonEmptyAnnotations(audio.Annotations)
 		return json.Marshal(struct {
 			Type ContentType `json:"type"`
-			*ResourceContent
+			*AudioContent
 		}{
-			Type:            ContentTypeResource,
-			ResourceContent: c.ResourceContent,
+			Type:         ContentTypeAudio,
+			AudioContent: &audio,
 		})
+	case ContentTypeResource:
+		if c.ResourceContent == nil {
+			return nil, fmt.Errorf("resource content is nil")
+		}
+		// ResourceContent has its own marshaler, which embedding would promote
+		// over the type field, so add the field afterwards
+		return marshalCustomContent(ContentTypeResource, c.ResourceContent)
 	default:
-		return nil, fmt.Errorf("unknown content type: %s", c.Type)
+		if _, ok := customContentFactory(c.Type); !ok {
+			return nil, fmt.Errorf("unknown content type: %s", c.Type)
+		}
+		if c.CustomContent == nil {
+			return nil, fmt.Errorf("%s content is nil", c.Type)
+		}
+		return marshalCustomContent(c.Type, c.CustomContent)
+	}
+}
+
+// marshalCustomContent encodes a custom content value as a JSON object and adds the type field
+func marshalCustomContent(ct ContentType, custom interface{}) ([]byte, error) {
+	data, err := json.Marshal(custom)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s content: %w", ct, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%s content must marshal to a JSON object: %w", ct, err)
 	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	typeField, err := json.Marshal(ct)
+	if err != nil {
+		return nil, err
+	}
+	fields["type"] = typeField
+
+	return json.Marshal(fields)
 }
 
 // Helper constructors
@@ -177,6 +419,78 @@ func NewImageContent(data, mimeType string, annotations *Annotations) *Content {
 	}
 }
 
+func NewAudioContent(data, mimeType string, annotations *Annotations) *Content {
+	return &Content{
+		Type: ContentTypeAudio,
+		AudioContent: &AudioContent{
+			Data:        data,
+			MimeType:    mimeType,
+			Annotations: annotations,
+		},
+	}
+}
+
+// ApplyAnnotations merges a into the annotations of every content block.
+// Fields set on a override the block's own values; unset fields are kept.
+// Each block receives its own copy, so later edits don't leak across blocks.
+func ApplyAnnotations(contents []*Content, a *Annotations) {
+	if a == nil {
+		return
+	}
+
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		switch c.Type {
+		case ContentTypeText:
+			if c.TextContent != nil {
+				c.TextContent.Annotations = mergeAnnotations(c.TextContent.Annotations, a)
+			}
+		case ContentTypeImage:
+			if c.ImageContent != nil {
+				c.ImageContent.Annotations = mergeAnnotations(c.ImageContent.Annotations, a)
+			}
+		case ContentTypeAudio:
+			if c.AudioContent != nil {
+				c.AudioContent.Annotations = mergeAnnotations(c.AudioContent.Annotations, a)
+			}
+		case ContentTypeResource:
+			if c.ResourceContent != nil {
+				c.ResourceContent.Annotations = mergeAnnotations(c.ResourceContent.Annotations, a)
+			}
+		}
+	}
+}
+
+func mergeAnnotations(base, overlay *Annotations) *Annotations {
+	merged := &Annotations{}
+	if base != nil {
+		merged.Audience = append([]Role(nil), base.Audience...)
+		if base.Priority != nil {
+			p := *base.Priority
+			merged.Priority = &p
+		}
+	}
+
+	if len(overlay.Audience) > 0 {
+		merged.Audience = append([]Role(nil), overlay.Audience...)
+	}
+	if overlay.Priority != nil {
+		p := *overlay.Priority
+		merged.Priority = &p
+	}
+	if overlay.Source != nil {
+		src := *overlay.Source
+		merged.Source = &src
+	} else if base != nil && base.Source != nil {
+		src := *base.Source
+		merged.Source = &src
+	}
+
+	return merged
+}
+
 /* Usage Example:
 message := Content{
     Type: ContentTypeText,
@@ -211,7 +525,33 @@ case ContentTypeText:
     fmt.Println(content.TextContent.Text)
 case ContentTypeImage:
     fmt.Println(content.ImageContent.MimeType)
+case ContentTypeAudio:
+    fmt.Println(content.AudioContent.MimeType)
 case ContentTypeResource:
     fmt.Println(content.ResourceContent.URI)
 }
+
+// Registering an experimental content type:
+type ChartContent struct {
+    Series []float64 `json:"series"`
+}
+
+if err := RegisterContentType("x-chart", func() interface{} { return &ChartContent{} }); err != nil {
+    log.Fatal(err)
+}
+
+var chart Content
+json.Unmarshal([]byte(`{"type":"x-chart","series":[1,2,3]}`), &chart)
+series := chart.CustomContent.(*ChartContent).Series
+
+// Marking a whole assistant turn as assistant-only:
+turn := []*Content{
+    NewTextContent("Here is the chart:", nil),
+    NewImageContent(chartData, "image/png", nil),
+}
+ApplyAnnotations(turn, &Annotations{Audience: []Role{RoleAssistant}})
+
+// Repairing annotations from a noisy upstream instead of rejecting them:
+upstream := &Annotations{Priority: ptr(1.7), Audience: []Role{RoleUser, "system"}}
+fixed := upstream.Clamp() // 2: priority is now 1, "system" was dropped
 */
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEmptyAnnotationsOmitted(t *testing.T) {
+	text := "hello"
+	resource := func(a *Annotations) interface{} {
+		return Resource{URI: "file:///a.txt", Name: "a", Annotations: a}
+	}
+	template := func(a *Annotations) interface{} {
+		return ResourceTemplate{Name: "files", URITemplate: "file:///{path}", Annotations: a}
+	}
+	embedded := func(a *Annotations) interface{} {
+		return Content{Type: ContentTypeResource, ResourceContent: &ResourceContent{URI: "file:///a.txt", Text: &text, Annotations: a}}
+	}
+
+	tests := []struct {
+		name  string
+		build func(*Annotations) interface{}
+	}{
+		{"text", func(a *Annotations) interface{} { return *NewTextContent(text, a) }},
+		{"image", func(a *Annotations) interface{} { return *NewImageContent("aGk=", "image/png", a) }},
+		{"audio", func(a *Annotations) interface{} { return *NewAudioContent("aGk=", "audio/wav", a) }},
+		{"resource", resource},
+		{"resource template", template},
+		{"embedded resource", embedded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, a := range []*Annotations{nil, {}, {Audience: []Role{}}} {
+				if fields := marshalFields(t, tt.build(a)); fields["annotations"] != nil {
+					t.Errorf("annotations %+v marshaled as %s", a, fields["annotations"])
+				}
+			}
+
+			priority := 0.5
+			if fields := marshalFields(t, tt.build(&Annotations{Priority: &priority})); fields["annotations"] == nil {
+				t.Error("non-empty annotations were omitted")
+			}
+		})
+	}
+}
+
+func marshalFields(t *testing.T, v interface{}) map[string]json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
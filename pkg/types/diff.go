@@ -0,0 +1,215 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diffContextLines is how many unchanged lines surround a change in a patch
+const diffContextLines = 3
+
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// DiffResourceContent produces a unified diff turning old's text into new's, for
+// sending in a ResourceUpdatedNotification instead of the full content. The diff
+// is a single hunk spanning the changed region, so it is cheap to compute and
+// small for localized edits. ok is false when either side is not text, the URIs
+// differ, or the patch would not be smaller than the new text. Identical
+// contents yield an empty patch.
+func DiffResourceContent(old, new *ResourceContent) (patch string, ok bool) {
+	if old == nil || new == nil || old.Text == nil || new.Text == nil || old.URI != new.URI {
+		return "", false
+	}
+	if *old.Text == *new.Text {
+		return "", true
+	}
+
+	a := splitLines(*old.Text)
+	b := splitLines(*new.Text)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	before := prefix
+	if before > diffContextLines {
+		before = diffContextLines
+	}
+	after := suffix
+	if after > diffContextLines {
+		after = diffContextLines
+	}
+
+	start := prefix - before
+	oldEnd := len(a) - suffix + after
+	newEnd := len(b) - suffix + after
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(start, oldEnd-start), hunkRange(start, newEnd-start))
+	for _, line := range a[start:prefix] {
+		writePatchLine(&sb, ' ', line)
+	}
+	for _, line := range a[prefix : len(a)-suffix] {
+		writePatchLine(&sb, '-', line)
+	}
+	for _, line := range b[prefix : len(b)-suffix] {
+		writePatchLine(&sb, '+', line)
+	}
+	for _, line := range a[len(a)-suffix : oldEnd] {
+		writePatchLine(&sb, ' ', line)
+	}
+
+	patch = sb.String()
+	if len(patch) >= len(*new.Text) {
+		return "", false
+	}
+	return patch, true
+}
+
+// ApplyResourcePatch applies a patch from DiffResourceContent to old and returns
+// the updated content. It fails if the patch does not match old's text.
+func ApplyResourcePatch(old *ResourceContent, patch string) (*ResourceContent, error) {
+	if old == nil || old.Text == nil {
+		return nil, fmt.Errorf("patches can only be applied to text content")
+	}
+
+	updated := *old
+	if patch == "" {
+		return &updated, nil
+	}
+
+	header, body, found := strings.Cut(patch, "\n")
+	if !found {
+		return nil, fmt.Errorf("malformed patch: missing hunk body")
+	}
+	oldStart, oldCount, err := parseHunkHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	a := splitLines(*old.Text)
+	if oldStart+oldCount > len(a) {
+		return nil, fmt.Errorf("patch does not apply: hunk exceeds content")
+	}
+
+	var removed, added []string
+	var last byte
+	for _, line := range splitLines(body) {
+		if line == noNewlineMarker {
+			// the preceding line has no trailing newline
+			switch last {
+			case ' ':
+				removed[len(removed)-1] = strings.TrimSuffix(removed[len(removed)-1], "\n")
+				added[len(added)-1] = strings.TrimSuffix(added[len(added)-1], "\n")
+			case '-':
+				removed[len(removed)-1] = strings.TrimSuffix(removed[len(removed)-1], "\n")
+			case '+':
+				added[len(added)-1] = strings.TrimSuffix(added[len(added)-1], "\n")
+			default:
+				return nil, fmt.Errorf("malformed patch: misplaced end-of-file marker")
+			}
+			continue
+		}
+
+		last = line[0]
+		switch last {
+		case ' ':
+			removed = append(removed, line[1:])
+			added = append(added, line[1:])
+		case '-':
+			removed = append(removed, line[1:])
+		case '+':
+			added = append(added, line[1:])
+		default:
+			return nil, fmt.Errorf("malformed patch line: %q", line)
+		}
+	}
+
+	if len(removed) != oldCount {
+		return nil, fmt.Errorf("malformed patch: hunk header expects %d old lines, body has %d", oldCount, len(removed))
+	}
+	for i, line := range removed {
+		if a[oldStart+i] != line {
+			return nil, fmt.Errorf("patch does not apply at line %d", oldStart+i+1)
+		}
+	}
+
+	var sb strings.Builder
+	for _, line := range a[:oldStart] {
+		sb.WriteString(line)
+	}
+	for _, line := range added {
+		sb.WriteString(line)
+	}
+	for _, line := range a[oldStart+oldCount:] {
+		sb.WriteString(line)
+	}
+
+	text := sb.String()
+	updated.Text = &text
+	return &updated, nil
+}
+
+// splitLines splits text into lines that keep their trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writePatchLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteByte('\n')
+		sb.WriteString(noNewlineMarker)
+	}
+}
+
+// hunkRange formats a 0-based start and line count as a unified diff range
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// parseHunkHeader returns the 0-based start and line count of the old range
+func parseHunkHeader(header string) (int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) != 4 || fields[0] != "@@" || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") {
+		return 0, 0, fmt.Errorf("malformed hunk header: %q", header)
+	}
+
+	startStr, countStr, found := strings.Cut(fields[1][1:], ",")
+	if !found {
+		countStr = "1"
+	}
+	start, err := strconv.Atoi(startStr)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("malformed hunk header: %q", header)
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 0 {
+		return 0, 0, fmt.Errorf("malformed hunk header: %q", header)
+	}
+
+	if count > 0 {
+		if start == 0 {
+			return 0, 0, fmt.Errorf("malformed hunk header: %q", header)
+		}
+		start--
+	}
+	return start, count, nil
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns lines "<word> 1" to "<word> n", each ending in a newline
+func numberedLines(word string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d\n", word, i+1)
+	}
+	return lines
+}
+
+// edit returns lines with the n lines at i replaced by insert
+func edit(lines []string, i, n int, insert ...string) string {
+	out := append([]string{}, lines[:i]...)
+	out = append(out, insert...)
+	out = append(out, lines[i+n:]...)
+	return strings.Join(out, "")
+}
+
+func textContent(text string) *ResourceContent {
+	return &ResourceContent{URI: "file:///doc.txt", Text: &text}
+}
+
+func TestDiffResourceContentRoundTrip(t *testing.T) {
+	base := numberedLines("line", 40)
+	text := strings.Join(base, "")
+	multibyte := numberedLines("héllo wörld ✓ 日本語", 40)
+	noNewline := strings.TrimSuffix(text, "\n")
+
+	tests := []struct {
+		name   string
+		old    string
+		new    string
+		wantOK bool
+	}{
+		{name: "both empty", old: "", new: "", wantOK: true},
+		{name: "identical", old: text, new: text, wantOK: true},
+		{name: "pure insertion", old: text, new: edit(base, 20, 0, "inserted\n"), wantOK: true},
+		{name: "insertion at the start", old: text, new: edit(base, 0, 0, "inserted\n"), wantOK: true},
+		{name: "insertion at the end", old: text, new: text + "appended\n", wantOK: true},
+		{name: "pure deletion", old: text, new: edit(base, 10, 2), wantOK: true},
+		{name: "deletion at the end", old: text, new: edit(base, 39, 1), wantOK: true},
+		{name: "replacement", old: text, new: edit(base, 15, 1, "changed\n"), wantOK: true},
+		{name: "multibyte", old: strings.Join(multibyte, ""), new: edit(multibyte, 5, 1, "ünïcödé ✗ 中文\n"), wantOK: true},
+		{name: "last line without newline", old: noNewline, new: noNewline + " and more", wantOK: true},
+		{name: "newline added at the end", old: noNewline, new: text, wantOK: true},
+		{name: "newline removed at the end", old: text, new: noNewline, wantOK: true},
+		// a patch never beats sending text that is empty or entirely new
+		{name: "empty to text", old: "", new: "first\n", wantOK: false},
+		{name: "text to empty", old: text, new: "", wantOK: false},
+		{name: "complete rewrite", old: text, new: strings.Join(numberedLines("other", 40), ""), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := textContent(tt.old), textContent(tt.new)
+			patch, ok := DiffResourceContent(before, after)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v (patch %q)", ok, tt.wantOK, patch)
+			}
+			if !ok {
+				return
+			}
+			if tt.old == tt.new && patch != "" {
+				t.Errorf("patch for identical text = %q, want empty", patch)
+			}
+
+			applied, err := ApplyResourcePatch(before, patch)
+			if err != nil {
+				t.Fatalf("applying %q: %v", patch, err)
+			}
+			if *applied.Text != tt.new {
+				t.Errorf("applied text = %q, want %q", *applied.Text, tt.new)
+			}
+			if applied.URI != before.URI || *before.Text != tt.old {
+				t.Error("applying a patch must keep the URI and leave the old content alone")
+			}
+		})
+	}
+}
+
+func TestApplyResourcePatchRejectsWrongBase(t *testing.T) {
+	base := numberedLines("line", 40)
+	old := textContent(strings.Join(base, ""))
+	patch, ok := DiffResourceContent(old, textContent(edit(base, 20, 1, "changed\n")))
+	if !ok {
+		t.Fatal("expected a patch")
+	}
+
+	tests := []struct {
+		name string
+		base string
+	}{
+		{name: "different context", base: edit(base, 19, 1, "drifted\n")},
+		{name: "different removed line", base: edit(base, 20, 1, "drifted\n")},
+		{name: "shorter text", base: strings.Join(base[:10], "")},
+		{name: "patch already applied", base: edit(base, 20, 1, "changed\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyResourcePatch(textContent(tt.base), patch); err == nil {
+				t.Error("patch applied to the wrong base")
+			}
+		})
+	}
+
+	if _, err := ApplyResourcePatch(&ResourceContent{URI: old.URI, Blob: new(string)}, patch); err == nil {
+		t.Error("patch applied to blob content")
+	}
+}
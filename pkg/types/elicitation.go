@@ -0,0 +1,161 @@
+package types
+
+import (
+	"fmt"
+)
+
+const MethodElicit = "elicitation/create"
+
+// ElicitAction is the user's response to an elicitation request
+type ElicitAction string
+
+const (
+	ElicitActionAccept  ElicitAction = "accept"
+	ElicitActionDecline ElicitAction = "decline"
+	ElicitActionCancel  ElicitAction = "cancel"
+)
+
+// ElicitRequestOption configures ElicitRequest
+type ElicitRequestOption func(*ElicitRequest) error
+
+// ElicitRequest represents a server request for structured input from the user
+type ElicitRequest struct {
+	Method string       `json:"method"`
+	Params ElicitParams `json:"params"`
+}
+
+type ElicitParams struct {
+	Message         string     `json:"message"`
+	RequestedSchema JSONSchema `json:"requestedSchema"`
+}
+
+func NewElicitRequest(message string, schema JSONSchema, opts ...ElicitRequestOption) (*ElicitRequest, error) {
+	if message == "" {
+		return nil, fmt.Errorf("elicitation message cannot be empty")
+	}
+
+	if err := validateRequestedSchema(schema); err != nil {
+		return nil, fmt.Errorf("invalid requested schema: %w", err)
+	}
+
+	req := &ElicitRequest{
+		Method: MethodElicit,
+		Params: ElicitParams{
+			Message:         message,
+			RequestedSchema: schema,
+		},
+	}
+
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, fmt.Errorf("applying elicit request option: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// validateRequestedSchema enforces that elicitation schemas are flat objects
+// with primitive properties only
+func validateRequestedSchema(schema JSONSchema) error {
+	if schema.Type != TypeObject {
+		return fmt.Errorf("schema type must be %s, got %s", TypeObject, schema.Type)
+	}
+
+	for name, prop := range schema.Properties {
+		switch prop.Type {
+		case TypeString, TypeNumber, TypeInteger, TypeBoolean:
+			// primitive types
+		default:
+			return fmt.Errorf("property %s must be a primitive type, got %s", name, prop.Type)
+		}
+	}
+
+	return nil
+}
+
+// ElicitResult represents the client's response to an elicitation request
+type ElicitResult struct {
+	Action  ElicitAction           `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+func NewElicitResult(action ElicitAction, content map[string]interface{}) (*ElicitResult, error) {
+	result := &ElicitResult{
+		Action:  action,
+		Content: content,
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *ElicitResult) Validate() error {
+	switch r.Action {
+	case ElicitActionAccept:
+		// content is checked against the requested schema by ValidateAgainst
+	case ElicitActionDecline, ElicitActionCancel:
+		if len(r.Content) > 0 {
+			return fmt.Errorf("content is only allowed when action is %s", ElicitActionAccept)
+		}
+	default:
+		return fmt.Errorf("invalid elicit action: %s", r.Action)
+	}
+
+	return nil
+}
+
+// ValidateAgainst checks the result and, on accept, that its content conforms to the requested schema
+func (r *ElicitResult) ValidateAgainst(schema JSONSchema) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	if r.Action != ElicitActionAccept {
+		return nil
+	}
+
+	content := r.Content
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+
+	return schema.Validate(content)
+}
+
+/* Usage Example:
+func ExampleElicitation() {
+    // Server asks the user for deployment details
+    request, err := NewElicitRequest(
+        "Which environment should I deploy to?",
+        JSONSchema{
+            Type: TypeObject,
+            Properties: map[string]JSONSchema{
+                "environment": NewStringEnum("dev", "staging", "prod"),
+                "replicas":    IntegerSchema,
+            },
+            Required: []string{"environment"},
+        },
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Client returns the user's answer
+    result, err := NewElicitResult(ElicitActionAccept, map[string]interface{}{
+        "environment": "staging",
+        "replicas":    2,
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Server checks the answer against what it asked for
+    if err := result.ValidateAgainst(request.Params.RequestedSchema); err != nil {
+        log.Fatal(err)
+    }
+}
+*/
@@ -3,6 +3,8 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 )
 
 const (
@@ -11,6 +13,11 @@ const (
 	ErrMethodNotFound = -32601
 	ErrInvalidParams  = -32602
 	ErrInternal       = -32603
+
+	// Server-defined errors
+	ErrServerNotInitialized = -32002
+	ErrUnauthorized         = -32003
+	ErrRateLimited          = -32029
 )
 
 // ErrorData represents different types of error details
@@ -21,6 +28,7 @@ type ErrorData interface {
 
 type ValidationFailure struct {
 	Field string `json:"field"`
+	Path  string `json:"path,omitempty"` // JSON Pointer to the failing value, e.g. /ports/0/protocol
 	Error string `json:"error"`
 }
 
@@ -31,6 +39,54 @@ type ValidationError struct {
 func (ValidationError) isErrorData()      {}
 func (ValidationError) ErrorType() string { return "validation" }
 
+// Error implements the error interface so validation failures can be returned directly
+func (v ValidationError) Error() string {
+	msgs := make([]string, len(v.Validation))
+	for i, f := range v.Validation {
+		switch {
+		case f.Path != "":
+			msgs[i] = fmt.Sprintf("%s: %s", f.Path, f.Error)
+		case f.Field != "":
+			msgs[i] = fmt.Sprintf("%s: %s", f.Field, f.Error)
+		default:
+			msgs[i] = f.Error
+		}
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ProblemDetail renders the failures as an RFC 7807 problem details object for
+// HTTP gateways, to be served as application/problem+json. Each failure becomes
+// an entry of the "errors" extension member.
+func (v ValidationError) ProblemDetail(status int) map[string]interface{} {
+	errs := make([]map[string]interface{}, len(v.Validation))
+	for i, f := range v.Validation {
+		entry := map[string]interface{}{
+			"detail": f.Error,
+		}
+		if f.Field != "" {
+			entry["field"] = f.Field
+		}
+		if f.Path != "" {
+			entry["pointer"] = f.Path
+		}
+		errs[i] = entry
+	}
+
+	title := http.StatusText(status)
+	if title == "" {
+		title = "Invalid parameters"
+	}
+
+	return map[string]interface{}{
+		"type":   "about:blank",
+		"title":  title,
+		"status": status,
+		"detail": v.Error(),
+		"errors": errs,
+	}
+}
+
 type ToolExecutionError struct {
 	ToolName string `json:"toolName"`
 	ErrType  string `json:"errorType"`
@@ -40,6 +96,14 @@ type ToolExecutionError struct {
 func (ToolExecutionError) isErrorData()      {}
 func (ToolExecutionError) ErrorType() string { return "toolExecution" }
 
+// RateLimitError tells a rate-limited client how long to back off
+type RateLimitError struct {
+	RetryAfterSeconds int `json:"retryAfterSeconds"`
+}
+
+func (RateLimitError) isErrorData()      {}
+func (RateLimitError) ErrorType() string { return "rateLimit" }
+
 // ErrorInfo represents a JSON-RPC error
 type ErrorInfo struct {
 	Code    int       `json:"code"`
@@ -47,6 +111,11 @@ type ErrorInfo struct {
 	Data    ErrorData `json:"data,omitempty"`
 }
 
+// Error implements the error interface so ErrorInfo can be returned directly
+func (e *ErrorInfo) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
 // MarshalJSON implements custom marshaling for ErrorInfo
 func (e ErrorInfo) MarshalJSON() ([]byte, error) {
 	type Alias ErrorInfo
@@ -103,17 +172,23 @@ func (e *ErrorInfo) UnmarshalJSON(data []byte) error {
 				return err
 			}
 			e.Data = validationErr
+		case ErrRateLimited:
+			var rateErr RateLimitError
+			if err := json.Unmarshal(aux.Data, &rateErr); err != nil {
+				return err
+			}
+			e.Data = rateErr
 		case ErrInternal:
-			switch temp.ErrorType {
-			case "toolExecution":
-				var toolErr ToolExecutionError
-				if err := json.Unmarshal(aux.Data, &toolErr); err != nil {
-					return err
-				}
-				e.Data = toolErr
-			default:
+			// errorType carries the kind of tool failure, such as "timeout",
+			// so tool execution errors are recognised by their tool name
+			var toolErr ToolExecutionError
+			if err := json.Unmarshal(aux.Data, &toolErr); err != nil {
+				return err
+			}
+			if toolErr.ToolName == "" {
 				return fmt.Errorf("unknown error type: %s", temp.ErrorType)
 			}
+			e.Data = toolErr
 		}
 	}
 
@@ -129,6 +204,33 @@ func NewValidationError(failures []ValidationFailure) *ErrorInfo {
 	}
 }
 
+func NewServerNotInitializedError() *ErrorInfo {
+	return &ErrorInfo{
+		Code:    ErrServerNotInitialized,
+		Message: "Server not initialized",
+	}
+}
+
+// NewUnauthorizedError denies the caller access to a method
+func NewUnauthorizedError(method string) *ErrorInfo {
+	return &ErrorInfo{
+		Code:    ErrUnauthorized,
+		Message: fmt.Sprintf("Not authorized to call %s", method),
+	}
+}
+
+// NewRateLimitError asks the client to retry after the given number of seconds
+func NewRateLimitError(retryAfterSeconds int) *ErrorInfo {
+	if retryAfterSeconds < 0 {
+		retryAfterSeconds = 0
+	}
+	return &ErrorInfo{
+		Code:    ErrRateLimited,
+		Message: "Rate limit exceeded",
+		Data:    RateLimitError{RetryAfterSeconds: retryAfterSeconds},
+	}
+}
+
 func NewToolExecutionError(toolName, errorType, details string) *ErrorInfo {
 	return &ErrorInfo{
 		Code:    ErrInternal,
@@ -156,7 +258,13 @@ toolErr := NewToolExecutionError(
     "Operation timed out after 30s",
 )
 
-// Example 3: Deserializing error from JSON
+// Example 3: Rate limiting, with clients backing off before retrying
+rateErr := NewRateLimitError(30)
+if data, ok := rateErr.Data.(RateLimitError); ok {
+    time.Sleep(time.Duration(data.RetryAfterSeconds) * time.Second)
+}
+
+// Example 4: Deserializing error from JSON
 jsonData := `{
     "code": -32602,
     "message": "Invalid parameters",
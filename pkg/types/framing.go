@@ -0,0 +1,222 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Framing selects how messages are delimited on a byte stream
+type Framing int
+
+const (
+	// FramingNewline separates messages with a newline, as on stdio
+	FramingNewline Framing = iota
+	// FramingContentLength prefixes each message with LSP-style headers
+	FramingContentLength
+)
+
+// FrameReaderOption configures FrameReader
+type FrameReaderOption func(*FrameReader) error
+
+// FrameReader splits a byte stream into complete JSON-RPC messages. Messages
+// split across reads are buffered until complete. It is not safe for concurrent use.
+type FrameReader struct {
+	r       *bufio.Reader
+	framing Framing
+	maxSize int64
+}
+
+func NewFrameReader(r io.Reader, opts ...FrameReaderOption) (*FrameReader, error) {
+	if r == nil {
+		return nil, fmt.Errorf("reader cannot be nil")
+	}
+
+	fr := &FrameReader{
+		r:       bufio.NewReader(r),
+		framing: FramingNewline,
+		maxSize: DefaultMaxMessageSize,
+	}
+
+	for _, opt := range opts {
+		if err := opt(fr); err != nil {
+			return nil, fmt.Errorf("applying frame reader option: %w", err)
+		}
+	}
+
+	return fr, nil
+}
+
+// FrameReader options
+
+func WithFraming(framing Framing) FrameReaderOption {
+	return func(fr *FrameReader) error {
+		switch framing {
+		case FramingNewline, FramingContentLength:
+			fr.framing = framing
+			return nil
+		default:
+			return fmt.Errorf("unknown framing: %d", framing)
+		}
+	}
+}
+
+// WithMaxFrameSize overrides DefaultMaxMessageSize as the largest accepted message
+func WithMaxFrameSize(maxBytes int64) FrameReaderOption {
+	return func(fr *FrameReader) error {
+		if maxBytes <= 0 {
+			return fmt.Errorf("max frame size must be positive")
+		}
+		fr.maxSize = maxBytes
+		return nil
+	}
+}
+
+// Next returns the next complete message. It returns io.EOF when the stream ends
+// between messages and io.ErrUnexpectedEOF when it ends inside one. Messages
+// larger than the maximum frame size fail with ErrMessageTooLarge; the rest of
+// such a message is discarded, so the next call returns the message after it.
+func (fr *FrameReader) Next() (json.RawMessage, error) {
+	if fr.framing == FramingContentLength {
+		return fr.nextContentLength()
+	}
+	return fr.nextLine()
+}
+
+func (fr *FrameReader) nextLine() (json.RawMessage, error) {
+	for {
+		var line []byte
+		for {
+			chunk, err := fr.r.ReadSlice('\n')
+			if int64(len(line)+len(chunk)) > fr.maxSize+1 {
+				if err == bufio.ErrBufferFull {
+					fr.discardLine()
+				}
+				return nil, fmt.Errorf("%w: more than %d bytes", ErrMessageTooLarge, fr.maxSize)
+			}
+			line = append(line, chunk...)
+
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err == io.EOF {
+				if len(bytes.TrimSpace(line)) > 0 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				return nil, io.EOF
+			}
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			// tolerate blank lines between messages
+			continue
+		}
+		return json.RawMessage(line), nil
+	}
+}
+
+// discardLine skips the rest of the current line, including the newline. A
+// read error ends the line; the next read will see it again.
+func (fr *FrameReader) discardLine() {
+	for {
+		if _, err := fr.r.ReadSlice('\n'); err != bufio.ErrBufferFull {
+			return
+		}
+	}
+}
+
+func (fr *FrameReader) nextContentLength() (json.RawMessage, error) {
+	length := int64(-1)
+	first := true
+
+	for {
+		raw, err := fr.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			return nil, fmt.Errorf("header line exceeds %d bytes", fr.r.Size())
+		}
+		if err != nil {
+			if err == io.EOF && first && len(raw) == 0 {
+				return nil, io.EOF
+			}
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		first = false
+
+		line := strings.TrimRight(string(raw), "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header line: %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid Content-Length: %q", value)
+			}
+			length = n
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	if length > fr.maxSize {
+		// a short body surfaces as EOF on the next call
+		io.CopyN(io.Discard, fr.r, length)
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrMessageTooLarge, length, fr.maxSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(fr.r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+/* Usage Example:
+func ExampleFrameReader() {
+    // Newline-delimited messages from a subprocess's stdout
+    frames, err := NewFrameReader(cmdStdout)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    for {
+        msg, err := frames.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            log.Fatal(err)
+        }
+        handle(msg)
+    }
+
+    // LSP-style framing: Content-Length: N\r\n\r\n<json>
+    lspFrames, err := NewFrameReader(conn,
+        WithFraming(FramingContentLength),
+        WithMaxFrameSize(1<<20),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+*/
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFrameReaderSkipsOversizedMessages(t *testing.T) {
+	big := `{"data":"` + strings.Repeat("x", 5000) + `"}`
+	tests := []struct {
+		name    string
+		framing Framing
+		stream  string
+	}{
+		{"newline", FramingNewline, big + "\n" + `{"a":1}` + "\n"},
+		{"content length", FramingContentLength,
+			"Content-Length: 5011\r\n\r\n" + big + "Content-Length: 7\r\n\r\n" + `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fr, err := NewFrameReader(strings.NewReader(tt.stream), WithFraming(tt.framing), WithMaxFrameSize(100))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := fr.Next(); !errors.Is(err, ErrMessageTooLarge) {
+				t.Fatalf("err = %v, want ErrMessageTooLarge", err)
+			}
+			msg, err := fr.Next()
+			if err != nil {
+				t.Fatalf("Next after oversized message: %v", err)
+			}
+			if string(msg) != `{"a":1}` {
+				t.Errorf("message = %s, want the one after the oversized message", msg)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 )
 
 // InitializeRequestOption configures InitializeRequest
@@ -21,7 +23,7 @@ type InitializeParams struct {
 
 func NewInitializeRequest(clientInfo Implementation, opts ...InitializeRequestOption) (*InitializeRequest, error) {
     req := &InitializeRequest{
-        Method: "initialize",
+        Method: MethodInitialize,
         Params: InitializeParams{
             ProtocolVersion: LatestProtocolVersion,
             ClientInfo:      clientInfo,
@@ -62,10 +64,11 @@ type InitializeResultOption func(*InitializeResult) error
 
 // InitializeResult represents the server's response to initialization
 type InitializeResult struct {
-    ProtocolVersion string             `json:"protocolVersion"`
-    ServerInfo      Implementation     `json:"serverInfo"`
-    Capabilities    ServerCapabilities `json:"capabilities"`
-    Instructions    *string            `json:"instructions,omitempty"`
+    ProtocolVersion string                 `json:"protocolVersion"`
+    ServerInfo      Implementation         `json:"serverInfo"`
+    Capabilities    ServerCapabilities     `json:"capabilities"`
+    Instructions    *string                `json:"instructions,omitempty"`
+    Meta            map[string]interface{} `json:"_meta,omitempty"`
 }
 
 func NewInitializeResult(serverInfo Implementation, opts ...InitializeResultOption) (*InitializeResult, error) {
@@ -103,13 +106,34 @@ func WithInstructions(instructions string) InitializeResultOption {
     }
 }
 
+// WithResultMeta sets a _meta entry on the result, for details such as a
+// session token that have no structured field
+func WithResultMeta(key string, value interface{}) InitializeResultOption {
+    return func(r *InitializeResult) error {
+        if key == "" {
+            return fmt.Errorf("meta key cannot be empty")
+        }
+        if r.Meta == nil {
+            r.Meta = make(map[string]interface{})
+        }
+        r.Meta[key] = value
+        return nil
+    }
+}
+
+// ImplementationOption configures Implementation
+type ImplementationOption func(*Implementation) error
+
 // Implementation represents an MCP implementation
 type Implementation struct {
-    Name    string `json:"name"`
-    Version string `json:"version"`
+    Name    string  `json:"name"`
+    Version string  `json:"version"`
+    // Title and URL are optional display metadata for connection UIs
+    Title   *string `json:"title,omitempty"`
+    URL     *string `json:"url,omitempty"`
 }
 
-func NewImplementation(name, version string) (*Implementation, error) {
+func NewImplementation(name, version string, opts ...ImplementationOption) (*Implementation, error) {
     if name == "" {
         return nil, fmt.Errorf("implementation name cannot be empty")
     }
@@ -117,10 +141,45 @@ func NewImplementation(name, version string) (*Implementation, error) {
         return nil, fmt.Errorf("implementation version cannot be empty")
     }
 
-    return &Implementation{
+    impl := &Implementation{
         Name:    name,
         Version: version,
-    }, nil
+    }
+
+    for _, opt := range opts {
+        if err := opt(impl); err != nil {
+            return nil, fmt.Errorf("applying implementation option: %w", err)
+        }
+    }
+
+    return impl, nil
+}
+
+// Implementation options
+
+func WithImplementationTitle(title string) ImplementationOption {
+    return func(i *Implementation) error {
+        if strings.TrimSpace(title) == "" {
+            return fmt.Errorf("implementation title cannot be empty")
+        }
+        i.Title = &title
+        return nil
+    }
+}
+
+// WithImplementationURL sets the project website; it must be an absolute http(s) URL
+func WithImplementationURL(rawURL string) ImplementationOption {
+    return func(i *Implementation) error {
+        u, err := url.Parse(rawURL)
+        if err != nil {
+            return fmt.Errorf("invalid implementation URL: %w", err)
+        }
+        if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return fmt.Errorf("implementation URL must be an absolute http(s) URL, got %q", rawURL)
+        }
+        i.URL = &rawURL
+        return nil
+    }
 }
 
 // InitializedNotification represents the notification sent after initialization
@@ -140,7 +199,7 @@ func NewInitializedNotification(meta map[string]interface{}) *InitializedNotific
     }
 
     return &InitializedNotification{
-        Method: "notifications/initialized",
+        Method: MethodInitialized,
         Params: params,
     }
 }
@@ -175,6 +234,8 @@ func ExampleInitialize() {
     serverInfo, err := NewImplementation(
         "example-server",
         "2.0.0",
+        WithImplementationTitle("Example Server"),
+        WithImplementationURL("https://example.com/mcp"),
     )
     if err != nil {
         log.Fatal(err)
@@ -196,6 +257,7 @@ func ExampleInitialize() {
             - formatCode: Format source code
             - analyzeCode: Analyze code for issues
         `),
+        WithResultMeta("sessionToken", sessionToken),
     )
     if err != nil {
         log.Fatal(err)
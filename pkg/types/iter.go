@@ -0,0 +1,94 @@
+package types
+
+import (
+	"fmt"
+	"iter"
+)
+
+// AllResources walks every page of a resources/list result, calling fetch with
+// each NextCursor until it is unset. fetch receives a nil cursor for the first
+// page. A fetch error is yielded once and ends the walk, as does a server that
+// returns a cursor it already returned.
+func AllResources(fetch func(cursor *string) (*ListResourcesResult, error)) iter.Seq2[Resource, error] {
+	return walkPages(fetch, func(r *ListResourcesResult) ([]Resource, *string) {
+		return r.Resources, r.NextCursor
+	})
+}
+
+// AllResourceTemplates walks every page of a resources/templates/list result
+// like AllResources
+func AllResourceTemplates(fetch func(cursor *string) (*ListResourceTemplatesResult, error)) iter.Seq2[ResourceTemplate, error] {
+	return walkPages(fetch, func(r *ListResourceTemplatesResult) ([]ResourceTemplate, *string) {
+		return r.ResourceTemplates, r.NextCursor
+	})
+}
+
+// AllPrompts walks every page of a prompts/list result like AllResources
+func AllPrompts(fetch func(cursor *string) (*ListPromptsResult, error)) iter.Seq2[Prompt, error] {
+	return walkPages(fetch, func(r *ListPromptsResult) ([]Prompt, *string) {
+		return r.Prompts, r.NextCursor
+	})
+}
+
+// AllTools walks every page of a tools/list result like AllResources
+func AllTools(fetch func(cursor *string) (*ListToolsResult, error)) iter.Seq2[Tool, error] {
+	return walkPages(fetch, func(r *ListToolsResult) ([]Tool, *string) {
+		return r.Tools, r.NextCursor
+	})
+}
+
+func walkPages[P any, T any](fetch func(cursor *string) (*P, error), page func(*P) ([]T, *string)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if fetch == nil {
+			yield(zero, fmt.Errorf("fetch function cannot be nil"))
+			return
+		}
+
+		var cursor *string
+		seen := make(map[string]bool)
+		for {
+			result, err := fetch(cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if result == nil {
+				yield(zero, fmt.Errorf("fetch returned no result"))
+				return
+			}
+
+			items, next := page(result)
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == nil {
+				return
+			}
+			if seen[*next] {
+				yield(zero, fmt.Errorf("server repeated cursor %q", *next))
+				return
+			}
+			seen[*next] = true
+			cursor = next
+		}
+	}
+}
+
+/* Usage Example:
+func ExampleAllResources() {
+    fetch := func(cursor *string) (*ListResourcesResult, error) {
+        return client.ListResources(ctx, cursor)
+    }
+
+    for resource, err := range AllResources(fetch) {
+        if err != nil {
+            log.Fatal(err)
+        }
+        fmt.Println(resource.URI)
+    }
+}
+*/
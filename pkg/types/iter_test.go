@@ -0,0 +1,136 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// pagedTools serves tools/list pages in order, page i answering the cursor
+// "p<i>". The final page may repeat a cursor to simulate a broken server.
+type pagedTools struct {
+	pages   [][]string
+	last    *string // NextCursor of the final page
+	cursors []string
+}
+
+func (p *pagedTools) fetch(cursor *string) (*ListToolsResult, error) {
+	i := 0
+	label := "<nil>"
+	if cursor != nil {
+		label = *cursor
+		if _, err := fmt.Sscanf(*cursor, "p%d", &i); err != nil {
+			return nil, err
+		}
+	}
+	p.cursors = append(p.cursors, label)
+	if i >= len(p.pages) {
+		return nil, fmt.Errorf("no page for cursor %s", label)
+	}
+
+	result := &ListToolsResult{}
+	for _, name := range p.pages[i] {
+		result.Tools = append(result.Tools, Tool{Name: name})
+	}
+	if i+1 < len(p.pages) {
+		next := fmt.Sprintf("p%d", i+1)
+		result.NextCursor = &next
+	} else {
+		result.NextCursor = p.last
+	}
+	return result, nil
+}
+
+func TestAllTools(t *testing.T) {
+	repeat := "p1"
+
+	tests := []struct {
+		name        string
+		pages       [][]string
+		last        *string
+		stopAfter   int // break after this many tools; 0 walks everything
+		want        []string
+		wantCursors []string
+		wantErr     bool
+	}{
+		{
+			name:        "single page",
+			pages:       [][]string{{"a", "b"}},
+			want:        []string{"a", "b"},
+			wantCursors: []string{"<nil>"},
+		},
+		{
+			name:        "continues through cursors",
+			pages:       [][]string{{"a"}, {}, {"b", "c"}},
+			want:        []string{"a", "b", "c"},
+			wantCursors: []string{"<nil>", "p1", "p2"},
+		},
+		{
+			name:        "break in the first page fetches no more",
+			pages:       [][]string{{"a", "b"}, {"c"}},
+			stopAfter:   1,
+			want:        []string{"a"},
+			wantCursors: []string{"<nil>"},
+		},
+		{
+			name:        "break at a page boundary fetches no more",
+			pages:       [][]string{{"a"}, {"b"}, {"c"}},
+			stopAfter:   2,
+			want:        []string{"a", "b"},
+			wantCursors: []string{"<nil>", "p1"},
+		},
+		{
+			name:        "repeated cursor",
+			pages:       [][]string{{"a"}, {"b"}},
+			last:        &repeat,
+			want:        []string{"a", "b"},
+			wantCursors: []string{"<nil>", "p1"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &pagedTools{pages: tt.pages, last: tt.last}
+			var got []string
+			var errs []error
+			for tool, err := range AllTools(p.fetch) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				got = append(got, tool.Name)
+				if len(got) == tt.stopAfter {
+					break
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tools = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(p.cursors, tt.wantCursors) {
+				t.Errorf("cursors = %v, want %v", p.cursors, tt.wantCursors)
+			}
+			if tt.wantErr != (len(errs) == 1) || len(errs) > 1 {
+				t.Errorf("errors = %v, want one: %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllToolsFetchError(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	fetch := func(cursor *string) (*ListToolsResult, error) {
+		calls++
+		return nil, boom
+	}
+
+	var errs []error
+	for _, err := range AllTools(fetch) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], boom) || calls != 1 {
+		t.Errorf("errors = %v after %d fetches, want %v once", errs, calls, boom)
+	}
+}
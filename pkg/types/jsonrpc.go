@@ -0,0 +1,496 @@
+package types
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultMaxMessageSize is the default upper bound for a single decoded message
+const DefaultMaxMessageSize = 4 << 20 // 4 MiB
+
+// ErrMessageTooLarge is returned when a message exceeds the decoding size limit
+var ErrMessageTooLarge = errors.New("message exceeds size limit")
+
+// RequestID is a JSON-RPC request identifier, either a string or an integer
+type RequestID struct {
+	str   string
+	num   int64
+	isStr bool
+}
+
+func NewIntRequestID(id int64) RequestID {
+	return RequestID{num: id}
+}
+
+func NewStringRequestID(id string) RequestID {
+	return RequestID{str: id, isStr: true}
+}
+
+// IsString reports whether the ID holds a string value
+func (id RequestID) IsString() bool {
+	return id.isStr
+}
+
+// String returns the ID in its textual form
+func (id RequestID) String() string {
+	if id.isStr {
+		return id.str
+	}
+	return strconv.FormatInt(id.num, 10)
+}
+
+func (id RequestID) MarshalJSON() ([]byte, error) {
+	if id.isStr {
+		return json.Marshal(id.str)
+	}
+	return json.Marshal(id.num)
+}
+
+func (id *RequestID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = NewStringRequestID(s)
+		return nil
+	}
+
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("request ID must be a string or integer: %w", err)
+	}
+	*id = NewIntRequestID(n)
+	return nil
+}
+
+// JSONRPCRequest is the envelope of a request that expects a response
+type JSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      RequestID       `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCNotification is the envelope of a one-way message
+type JSONRPCNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCResponse is the envelope of a reply; exactly one of Result and Error is set
+type JSONRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      RequestID       `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ErrorInfo      `json:"error,omitempty"`
+}
+
+func (r *JSONRPCRequest) UnmarshalJSON(data []byte) error {
+	type Alias JSONRPCRequest
+	var aux struct {
+		Alias
+		ID *RequestID `json:"id"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if err := validateJSONRPCVersion(aux.JSONRPC); err != nil {
+		return err
+	}
+	if aux.ID == nil {
+		return newInvalidRequestError("request id is missing")
+	}
+	if aux.Method == "" {
+		return newInvalidRequestError("method is missing")
+	}
+
+	*r = JSONRPCRequest(aux.Alias)
+	r.ID = *aux.ID
+	return nil
+}
+
+func (n *JSONRPCNotification) UnmarshalJSON(data []byte) error {
+	type Alias JSONRPCNotification
+	var aux Alias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if err := validateJSONRPCVersion(aux.JSONRPC); err != nil {
+		return err
+	}
+	if aux.Method == "" {
+		return newInvalidRequestError("method is missing")
+	}
+
+	*n = JSONRPCNotification(aux)
+	return nil
+}
+
+func (r *JSONRPCResponse) UnmarshalJSON(data []byte) error {
+	type Alias JSONRPCResponse
+	var aux Alias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if err := validateJSONRPCVersion(aux.JSONRPC); err != nil {
+		return err
+	}
+
+	*r = JSONRPCResponse(aux)
+	return nil
+}
+
+func validateJSONRPCVersion(version string) error {
+	if version == "" {
+		return newInvalidRequestError("jsonrpc version is missing")
+	}
+	if version != JSONRPCVersion {
+		return newInvalidRequestError(fmt.Sprintf("unsupported jsonrpc version %q", version))
+	}
+	return nil
+}
+
+func newInvalidRequestError(details string) *ErrorInfo {
+	return &ErrorInfo{
+		Code:    ErrInvalidRequest,
+		Message: "Invalid request: " + details,
+	}
+}
+
+// IDGeneratorOption configures IDGenerator
+type IDGeneratorOption func(*IDGenerator) error
+
+// IDGenerator hands out unique request IDs. It is safe for concurrent use.
+type IDGenerator struct {
+	counter atomic.Int64
+	uuid    bool
+}
+
+func NewIDGenerator(opts ...IDGeneratorOption) (*IDGenerator, error) {
+	g := &IDGenerator{}
+
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, fmt.Errorf("applying id generator option: %w", err)
+		}
+	}
+
+	return g, nil
+}
+
+// IDGenerator options
+
+// WithUUIDs makes the generator produce random UUID string IDs instead of integers
+func WithUUIDs() IDGeneratorOption {
+	return func(g *IDGenerator) error {
+		g.uuid = true
+		return nil
+	}
+}
+
+// Next returns the next ID. Integer IDs increase monotonically starting at 1.
+func (g *IDGenerator) Next() RequestID {
+	if g.uuid {
+		return NewStringRequestID(newUUID())
+	}
+	return NewIntRequestID(g.counter.Add(1))
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("reading random bytes for uuid: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Correlator matches responses to the requests waiting for them, for peers
+// that send requests on one goroutine and read responses on another. It is
+// safe for concurrent use.
+type Correlator struct {
+	mu      sync.Mutex
+	pending map[RequestID]chan *JSONRPCResponse
+	err     error // set by Close
+}
+
+func NewCorrelator() *Correlator {
+	return &Correlator{pending: make(map[RequestID]chan *JSONRPCResponse)}
+}
+
+// Register starts waiting for the response to id. The channel receives the
+// response, or is closed without one when the correlator is closed. Register
+// before sending the request, so a fast response is not missed.
+func (c *Correlator) Register(id RequestID) (<-chan *JSONRPCResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	if _, ok := c.pending[id]; ok {
+		return nil, fmt.Errorf("request %s is already pending", id)
+	}
+	ch := make(chan *JSONRPCResponse, 1)
+	c.pending[id] = ch
+	return ch, nil
+}
+
+// Forget stops waiting for id, e.g. after the caller gave up. A response
+// arriving later is not delivered.
+func (c *Correlator) Forget(id RequestID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// Deliver hands resp to the caller waiting for its ID and reports whether
+// there was one
+func (c *Correlator) Deliver(resp *JSONRPCResponse) bool {
+	if resp == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.pending[resp.ID]
+	if !ok {
+		return false
+	}
+	delete(c.pending, resp.ID)
+	ch <- resp // buffered, and delivered at most once
+	return true
+}
+
+// Close wakes every waiting caller by closing its channel and makes Register
+// and Err return err. Only the first call has an effect.
+func (c *Correlator) Close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+// Err returns the error the correlator was closed with, or nil while it is open
+func (c *Correlator) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// DecodeWithLimit decodes a single JSON message from r into v, reading at most
+// maxBytes. Larger messages fail with ErrMessageTooLarge before being parsed.
+func DecodeWithLimit(r io.Reader, maxBytes int64, v interface{}) error {
+	if maxBytes <= 0 {
+		return fmt.Errorf("max bytes must be positive")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("reading message: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrMessageTooLarge, maxBytes)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Request is implemented by every typed request. GetMethod returns the method
+// the request is sent under, even if its Method field is unset.
+type Request interface {
+	GetMethod() string
+}
+
+func (*InitializeRequest) GetMethod() string            { return MethodInitialize }
+func (*SetLevelRequest) GetMethod() string              { return MethodSetLevel }
+func (*CompleteRequest) GetMethod() string              { return MethodComplete }
+func (*ElicitRequest) GetMethod() string                { return MethodElicit }
+func (*CallToolRequest) GetMethod() string              { return MethodCallTool }
+func (*ListResourceTemplatesRequest) GetMethod() string { return MethodListResourceTemplates }
+
+// MarshalRequestOption configures MarshalRequest
+type MarshalRequestOption func(*marshalRequestConfig)
+
+type marshalRequestConfig struct {
+	id  *RequestID
+	ids *IDGenerator
+}
+
+// WithRequestID sends the request under a fixed ID
+func WithRequestID(id RequestID) MarshalRequestOption {
+	return func(c *marshalRequestConfig) {
+		c.id = &id
+	}
+}
+
+// WithIDGenerator draws the request's ID from gen
+func WithIDGenerator(gen *IDGenerator) MarshalRequestOption {
+	return func(c *marshalRequestConfig) {
+		c.ids = gen
+	}
+}
+
+// defaultIDs numbers requests marshaled without an ID option
+var defaultIDs IDGenerator
+
+// MarshalRequest wraps a typed request in a JSON-RPC envelope under its
+// canonical method. An unset Method field is filled in; one that disagrees with
+// GetMethod is an error. Without an ID option the ID comes from a package-wide
+// generator, so callers that also number requests themselves should pass
+// WithIDGenerator to avoid collisions.
+func MarshalRequest(r Request, opts ...MarshalRequestOption) ([]byte, error) {
+	if r == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	cfg := marshalRequestConfig{ids: &defaultIDs}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	declared, params, err := messageFields(r, r.GetMethod())
+	if err != nil {
+		return nil, err
+	}
+	if declared != "" && declared != r.GetMethod() {
+		return nil, fmt.Errorf("request method %q does not match %q", declared, r.GetMethod())
+	}
+
+	var id RequestID
+	if cfg.id != nil {
+		id = *cfg.id
+	} else {
+		id = cfg.ids.Next()
+	}
+
+	data, err := json.Marshal(JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Method:  r.GetMethod(),
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s request: %w", r.GetMethod(), err)
+	}
+	return data, nil
+}
+
+// messageFields marshals a typed message and returns its method and params
+// members. Null params are dropped.
+func messageFields(v interface{}, method string) (string, json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshaling %s message: %w", method, err)
+	}
+
+	var fields struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", nil, fmt.Errorf("%s message must marshal to a JSON object: %w", method, err)
+	}
+
+	if len(fields.Params) == 0 || string(fields.Params) == "null" {
+		return fields.Method, nil, nil
+	}
+	return fields.Method, fields.Params, nil
+}
+
+/* Usage Example:
+func ExampleIDGenerator() {
+    gen, err := NewIDGenerator()
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    first := gen.Next()  // 1
+    second := gen.Next() // 2
+
+    // String IDs for peers that prefer opaque identifiers
+    uuidGen, err := NewIDGenerator(WithUUIDs())
+    if err != nil {
+        log.Fatal(err)
+    }
+    id := uuidGen.Next() // e.g. "3f0c2a9e-6b1d-4e8a-9c3f-2d7b5e1a0c44"
+
+    data, _ := json.Marshal(id) // "\"3f0c2a9e-...\""
+}
+
+func ExampleCorrelator() {
+    calls := NewCorrelator()
+
+    // Reader goroutine: route responses to their callers
+    go func() {
+        for {
+            data, err := transport.Read(ctx)
+            if err != nil {
+                calls.Close(err)
+                return
+            }
+            var resp JSONRPCResponse
+            if json.Unmarshal(data, &resp) == nil {
+                calls.Deliver(&resp)
+            }
+        }
+    }()
+
+    // Caller: register, send, then wait
+    id := gen.Next()
+    ch, err := calls.Register(id)
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer calls.Forget(id)
+    data, _ := MarshalRequest(&CallToolRequest{Params: CallToolParams{Name: "search"}}, WithRequestID(id))
+    transport.Write(ctx, data)
+
+    resp, ok := <-ch
+    if !ok {
+        log.Fatal(calls.Err())
+    }
+}
+
+func ExampleMarshalRequest() {
+    gen, _ := NewIDGenerator()
+
+    // A struct literal without Method is still sent as tools/call
+    data, err := MarshalRequest(&CallToolRequest{
+        Params: CallToolParams{Name: "search"},
+    }, WithIDGenerator(gen))
+    if err != nil {
+        log.Fatal(err)
+    }
+    // {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}}
+}
+
+func ExampleDecodeWithLimit(w http.ResponseWriter, r *http.Request) {
+    var req CallToolRequest
+    err := DecodeWithLimit(r.Body, DefaultMaxMessageSize, &req)
+    if errors.Is(err, ErrMessageTooLarge) {
+        http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+        return
+    }
+}
+*/
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCorrelator(t *testing.T) {
+	calls := NewCorrelator()
+	id := NewIntRequestID(1)
+
+	ch, err := calls.Register(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calls.Register(id); err == nil {
+		t.Error("registering a pending ID twice should fail")
+	}
+	if calls.Deliver(&JSONRPCResponse{ID: NewIntRequestID(2)}) {
+		t.Error("delivered a response nobody waits for")
+	}
+	if !calls.Deliver(&JSONRPCResponse{ID: id}) {
+		t.Fatal("response was not delivered")
+	}
+	if resp := <-ch; resp.ID != id {
+		t.Errorf("received response for %s, want %s", resp.ID, id)
+	}
+	if calls.Deliver(&JSONRPCResponse{ID: id}) {
+		t.Error("delivered a second response for the same ID")
+	}
+
+	waiting, err := calls.Register(NewIntRequestID(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := errors.New("transport closed")
+	calls.Close(closed)
+	if _, ok := <-waiting; ok {
+		t.Error("pending channel received a response after Close")
+	}
+	if err := calls.Err(); err != closed {
+		t.Errorf("Err() = %v, want %v", err, closed)
+	}
+	if _, err := calls.Register(NewIntRequestID(4)); err != closed {
+		t.Errorf("Register after Close = %v, want %v", err, closed)
+	}
+}
+
+func TestMarshalRequestIDs(t *testing.T) {
+	gen, err := NewIDGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewCallToolRequest("search", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts []MarshalRequestOption
+		want RequestID
+	}{
+		// a fixed ID leaves the generator alone
+		{name: "fixed id", opts: []MarshalRequestOption{WithIDGenerator(gen), WithRequestID(NewStringRequestID("fixed"))}, want: NewStringRequestID("fixed")},
+		{name: "generated id", opts: []MarshalRequestOption{WithIDGenerator(gen)}, want: NewIntRequestID(1)},
+		{name: "next generated id", opts: []MarshalRequestOption{WithIDGenerator(gen)}, want: NewIntRequestID(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalRequest(req, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var envelope JSONRPCRequest
+			if err := json.Unmarshal(data, &envelope); err != nil {
+				t.Fatal(err)
+			}
+			if envelope.ID != tt.want {
+				t.Errorf("id = %s, want %s", envelope.ID, tt.want)
+			}
+		})
+	}
+}
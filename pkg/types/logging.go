@@ -1,7 +1,10 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 )
 
 // LoggingLevel represents the severity of log messages
@@ -37,7 +40,7 @@ func NewSetLevelRequest(level LoggingLevel, opts ...SetLevelRequestOption) (*Set
     }
 
     req := &SetLevelRequest{
-        Method: "logging/setLevel",
+        Method: MethodSetLevel,
         Params: SetLevelParams{
             Level: level,
         },
@@ -62,6 +65,56 @@ func validateLoggingLevel(level LoggingLevel) error {
     }
 }
 
+// loggingLevelAliases maps common abbreviations, as used by syslog, to levels
+var loggingLevelAliases = map[string]LoggingLevel{
+    "warn":  LogLevelWarning,
+    "err":   LogLevelError,
+    "crit":  LogLevelCritical,
+    "emerg": LogLevelEmergency,
+}
+
+// ParseLoggingLevel parses a level from configuration such as an environment
+// variable or flag. Case and surrounding whitespace are ignored, and syslog
+// abbreviations like "warn" are accepted.
+func ParseLoggingLevel(s string) (LoggingLevel, error) {
+    name := strings.ToLower(strings.TrimSpace(s))
+    if level, ok := loggingLevelAliases[name]; ok {
+        return level, nil
+    }
+
+    level := LoggingLevel(name)
+    if err := validateLoggingLevel(level); err != nil {
+        return "", fmt.Errorf("invalid logging level %q", s)
+    }
+    return level, nil
+}
+
+// loggingSeverity orders levels from least (debug) to most (emergency) severe
+var loggingSeverity = map[LoggingLevel]int{
+    LogLevelDebug:     0,
+    LogLevelInfo:      1,
+    LogLevelNotice:    2,
+    LogLevelWarning:   3,
+    LogLevelError:     4,
+    LogLevelCritical:  5,
+    LogLevelAlert:     6,
+    LogLevelEmergency: 7,
+}
+
+// ShouldLog reports whether a message at level passes the threshold.
+// Unknown levels never pass.
+func ShouldLog(threshold, level LoggingLevel) bool {
+    min, ok := loggingSeverity[threshold]
+    if !ok {
+        return false
+    }
+    severity, ok := loggingSeverity[level]
+    if !ok {
+        return false
+    }
+    return severity >= min
+}
+
 // LoggingMessageOption configures LoggingMessage
 type LoggingMessageOption func(*LoggingMessageNotification) error
 
@@ -87,7 +140,7 @@ func NewLoggingMessage(level LoggingLevel, data interface{}, opts ...LoggingMess
     }
 
     msg := &LoggingMessageNotification{
-        Method: "notifications/message",
+        Method: MethodLoggingMessage,
         Params: LoggingMessageParams{
             Level: level,
             Data:  data,
@@ -115,6 +168,18 @@ func WithLogger(logger string) LoggingMessageOption {
     }
 }
 
+// WithValidatedData checks at construction that the data can be marshaled to
+// JSON, so payloads holding channels, funcs or cycles fail at the call site
+// rather than when the notification is sent
+func WithValidatedData() LoggingMessageOption {
+    return func(msg *LoggingMessageNotification) error {
+        if _, err := json.Marshal(msg.Params.Data); err != nil {
+            return fmt.Errorf("log data is not JSON-serializable: %w", err)
+        }
+        return nil
+    }
+}
+
 // Helper functions for creating log messages with specific levels
 
 func NewDebugMessage(data interface{}, opts ...LoggingMessageOption) (*LoggingMessageNotification, error) {
@@ -137,6 +202,143 @@ func NewCriticalMessage(data interface{}, opts ...LoggingMessageOption) (*Loggin
     return NewLoggingMessage(LogLevelCritical, data, opts...)
 }
 
+// LevelChangeListener is called after the effective logging level changes
+type LevelChangeListener func(old, new LoggingLevel)
+
+// Logger holds the current logging level and forwards messages at or above it to a sink
+type Logger struct {
+    mu        sync.RWMutex
+    level     LoggingLevel
+    listeners []LevelChangeListener
+    sink      func(*LoggingMessageNotification) error
+}
+
+func NewLogger(level LoggingLevel, sink func(*LoggingMessageNotification) error) (*Logger, error) {
+    if err := validateLoggingLevel(level); err != nil {
+        return nil, err
+    }
+    if sink == nil {
+        return nil, fmt.Errorf("logger sink cannot be nil")
+    }
+
+    return &Logger{
+        level: level,
+        sink:  sink,
+    }, nil
+}
+
+// Level returns the current logging level
+func (l *Logger) Level() LoggingLevel {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+    return l.level
+}
+
+// OnLevelChange registers a listener invoked whenever the level changes
+func (l *Logger) OnLevelChange(listener LevelChangeListener) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.listeners = append(l.listeners, listener)
+}
+
+// SetLevel changes the logging level and notifies listeners if it differs from the current one
+func (l *Logger) SetLevel(level LoggingLevel) error {
+    if err := validateLoggingLevel(level); err != nil {
+        return err
+    }
+
+    l.mu.Lock()
+    old := l.level
+    l.level = level
+    listeners := append([]LevelChangeListener(nil), l.listeners...)
+    l.mu.Unlock()
+
+    if old != level {
+        for _, listener := range listeners {
+            listener(old, level)
+        }
+    }
+
+    return nil
+}
+
+// ApplySetLevel applies a logging/setLevel request
+func (l *Logger) ApplySetLevel(req *SetLevelRequest) error {
+    if req == nil {
+        return fmt.Errorf("set level request cannot be nil")
+    }
+    return l.SetLevel(req.Params.Level)
+}
+
+// Emit sends a log message to the sink. Messages below the current level are dropped.
+func (l *Logger) Emit(level LoggingLevel, data interface{}, opts ...LoggingMessageOption) error {
+    if !ShouldLog(l.Level(), level) {
+        return nil
+    }
+
+    msg, err := NewLoggingMessage(level, data, opts...)
+    if err != nil {
+        return err
+    }
+
+    return l.sink(msg)
+}
+
+// RedactedValue replaces sensitive values in redacted arguments
+const RedactedValue = "[REDACTED]"
+
+// RedactArguments returns a copy of args with every value whose schema is marked
+// Sensitive replaced by RedactedValue. Nested objects and arrays are followed,
+// as are $refs into the schema's $defs; values without a matching schema are
+// kept.
+func RedactArguments(args map[string]interface{}, schema JSONSchema) map[string]interface{} {
+    if args == nil {
+        return nil
+    }
+
+    redacted, _ := redactValue(args, &schema, schema.Defs).(map[string]interface{})
+    return redacted
+}
+
+func redactValue(value interface{}, schema *JSONSchema, defs map[string]JSONSchema) interface{} {
+    if schema == nil {
+        return value
+    }
+    resolved := schema.resolveRefs(defs)
+    if resolved.Sensitive != nil && *resolved.Sensitive {
+        return RedactedValue
+    }
+
+    switch v := value.(type) {
+    case map[string]interface{}:
+        out := make(map[string]interface{}, len(v))
+        for key, item := range v {
+            if prop, ok := resolved.Properties[key]; ok {
+                out[key] = redactValue(item, &prop, defs)
+            } else {
+                out[key] = item
+            }
+        }
+        return out
+    case []interface{}:
+        out := make([]interface{}, len(v))
+        for i, item := range v {
+            out[i] = redactValue(item, resolved.Items, defs)
+        }
+        return out
+    default:
+        return value
+    }
+}
+
+// RedactCallToolRequest returns a copy of req that is safe to log, with the
+// arguments redacted according to the tool's input schema
+func RedactCallToolRequest(req *CallToolRequest, tool Tool) *CallToolRequest {
+    redacted := *req
+    redacted.Params.Arguments = RedactArguments(req.Params.Arguments, tool.InputSchema)
+    return &redacted
+}
+
 /* Usage Example:
 func ExampleLogging() {
     // Set logging level
@@ -145,6 +347,16 @@ func ExampleLogging() {
         log.Fatal(err)
     }
 
+    // Level from the environment, e.g. LOG_LEVEL=WARN
+    level, err := ParseLoggingLevel(os.Getenv("LOG_LEVEL"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    envLevelReq, err := NewSetLevelRequest(level)
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Create simple info message
     infoMsg, err := NewInfoMessage(
         "Server started successfully",
@@ -192,6 +404,21 @@ func ExampleLogging() {
         log.Fatal(err)
     }
 
+    // Route messages through a Logger that honors logging/setLevel
+    logger, err := NewLogger(LogLevelInfo, func(msg *LoggingMessageNotification) error {
+        return send(msg) // write to the transport
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+    logger.OnLevelChange(func(old, new LoggingLevel) {
+        fmt.Printf("log level changed from %s to %s\n", old, new)
+    })
+    if err := logger.ApplySetLevel(setLevelReq); err != nil {
+        log.Fatal(err)
+    }
+    logger.Emit(LogLevelDebug, "dropped, below info") // filtered out
+
     // Example JSON output for error message:
     // {
     //     "method": "notifications/message",
@@ -234,6 +461,11 @@ func ExampleStructuredLogging() {
         WithLogger("metrics"),
     )
 
+    // Fail fast on payloads that can't be sent
+    if _, err := NewInfoMessage(payload, WithValidatedData()); err != nil {
+        log.Printf("bad log payload: %v", err)
+    }
+
     // Create error log
     errorMsg, _ := NewErrorMessage(
         ErrorData{
@@ -243,5 +475,15 @@ func ExampleStructuredLogging() {
         },
         WithLogger("configuration"),
     )
+
+    // Log a tool call without leaking secrets
+    loginTool, _ := NewTool("login",
+        WithToolProperty("user", StringSchema),
+        WithToolProperty("password", StringSchemaWithConstraints(WithSensitive())),
+    )
+    callMsg, _ := NewDebugMessage(
+        RedactCallToolRequest(request, *loginTool), // password: "[REDACTED]"
+        WithLogger("tools"),
+    )
 }
 */
@@ -0,0 +1,32 @@
+package types
+
+import "testing"
+
+func TestRedactArgumentsFollowsRefs(t *testing.T) {
+	sensitive := true
+	tool, err := NewTool("login",
+		WithToolDefinitions(map[string]JSONSchema{
+			"credentials": ObjectSchema(map[string]JSONSchema{
+				"user":     StringSchema,
+				"password": {Type: TypeString, Sensitive: &sensitive},
+			}),
+		}),
+		WithToolPropertyRef("auth", DefsRefPrefix+"credentials"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]interface{}{
+		"auth": map[string]interface{}{"user": "ada", "password": "SECRET"},
+	}
+	redacted := RedactArguments(args, tool.InputSchema)
+
+	auth := redacted["auth"].(map[string]interface{})
+	if auth["password"] != RedactedValue {
+		t.Errorf("password = %v, want %s", auth["password"], RedactedValue)
+	}
+	if auth["user"] != "ada" {
+		t.Errorf("user = %v, want ada", auth["user"])
+	}
+}
@@ -1,7 +1,12 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 // Message represents a communication element in the protocol
@@ -18,7 +23,10 @@ func (m *Message) Validate() error {
 		return fmt.Errorf("invalid role: %s", m.Role)
 	}
 
-	// The Content type already handles its own validation through JSON marshaling
+	if err := m.Content.Validate(); err != nil {
+		return fmt.Errorf("invalid content: %w", err)
+	}
+
 	return nil
 }
 
@@ -39,11 +47,49 @@ type ModelPreferences struct {
 	IntelligencePriority *float64 `json:"intelligencePriority,omitempty"`
 }
 
-func (mp *ModelPreferences) Validate() error {
+// DefaultMaxModelHints caps the number of hints in ModelPreferences
+const DefaultMaxModelHints = 10
+
+// ModelPreferencesOption configures ModelPreferences validation
+type ModelPreferencesOption func(*modelPreferencesConfig)
+
+type modelPreferencesConfig struct {
+	maxHints int
+}
+
+// WithMaxModelHints overrides DefaultMaxModelHints
+func WithMaxModelHints(n int) ModelPreferencesOption {
+	return func(c *modelPreferencesConfig) {
+		c.maxHints = n
+	}
+}
+
+// Validate checks that priorities are within 0-1, that every hint names a model
+// and that there are no more hints than the cap. Preferences must set at least
+// one hint or priority, since an empty object tells the client nothing.
+func (mp *ModelPreferences) Validate(opts ...ModelPreferencesOption) error {
 	if mp == nil {
 		return nil
 	}
 
+	cfg := modelPreferencesConfig{maxHints: DefaultMaxModelHints}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if len(mp.Hints) == 0 && mp.CostPriority == nil && mp.SpeedPriority == nil && mp.IntelligencePriority == nil {
+		return fmt.Errorf("model preferences must set at least one hint or priority")
+	}
+
+	if len(mp.Hints) > cfg.maxHints {
+		return fmt.Errorf("too many model hints: %d exceeds limit of %d", len(mp.Hints), cfg.maxHints)
+	}
+	for i, hint := range mp.Hints {
+		if hint.Name == nil || strings.TrimSpace(*hint.Name) == "" {
+			return fmt.Errorf("model hint %d must have a non-empty name", i)
+		}
+	}
+
 	priorities := map[string]*float64{
 		"costPriority":         mp.CostPriority,
 		"speedPriority":        mp.SpeedPriority,
@@ -87,7 +133,9 @@ const (
 	IncludeContextAllServers IncludeContext = "allServers"
 )
 
-func (p *CreateMessageParams) Validate() error {
+// Validate checks the params; opts configure the validation of the model
+// preferences, e.g. WithMaxModelHints
+func (p *CreateMessageParams) Validate(opts ...ModelPreferencesOption) error {
 	if len(p.Messages) == 0 {
 		return fmt.Errorf("messages cannot be empty")
 	}
@@ -112,7 +160,7 @@ func (p *CreateMessageParams) Validate() error {
 	}
 
 	if p.ModelPreferences != nil {
-		if err := p.ModelPreferences.Validate(); err != nil {
+		if err := p.ModelPreferences.Validate(opts...); err != nil {
 			return fmt.Errorf("invalid model preferences: %w", err)
 		}
 	}
@@ -120,6 +168,170 @@ func (p *CreateMessageParams) Validate() error {
 	return nil
 }
 
+// ErrSamplingNotSupported is returned by CheckClientSupport when the client
+// did not advertise the sampling capability
+var ErrSamplingNotSupported = errors.New("client does not support sampling")
+
+// ErrUnsupportedSamplingContent is returned by CheckClientSupport for messages
+// holding content types the client did not advertise
+var ErrUnsupportedSamplingContent = errors.New("sampling content type not supported by client")
+
+// CheckClientSupport reports whether a client with the given capabilities can
+// take the request: it must advertise sampling and every message's content
+// type must be among those it supports
+func (p *CreateMessageParams) CheckClientSupport(caps ClientCapabilities) error {
+	if caps.Sampling == nil {
+		return ErrSamplingNotSupported
+	}
+	for i, msg := range p.Messages {
+		if !caps.Sampling.SupportsContentType(msg.Content.Type) {
+			return fmt.Errorf("%w: message %d has %s content", ErrUnsupportedSamplingContent, i, msg.Content.Type)
+		}
+	}
+	return nil
+}
+
+const MethodCreateMessage = "sampling/createMessage"
+
+// StopReason describes why sampling stopped
+type StopReason string
+
+const (
+	StopReasonEndTurn      StopReason = "endTurn"
+	StopReasonStopSequence StopReason = "stopSequence"
+	StopReasonMaxTokens    StopReason = "maxTokens"
+)
+
+// CreateMessageResult represents the client's response to a sampling request
+type CreateMessageResult struct {
+	Role       Role        `json:"role"`
+	Content    Content     `json:"content"`
+	Model      string      `json:"model"`
+	StopReason *StopReason `json:"stopReason,omitempty"`
+}
+
+func (r *CreateMessageResult) Validate() error {
+	switch r.Role {
+	case RoleUser, RoleAssistant:
+		// valid roles
+	default:
+		return fmt.Errorf("invalid role: %s", r.Role)
+	}
+
+	if r.Model == "" {
+		return fmt.Errorf("model cannot be empty")
+	}
+
+	if err := r.Content.Validate(); err != nil {
+		return fmt.Errorf("invalid content: %w", err)
+	}
+
+	return nil
+}
+
+// SamplingHandler is implemented by clients to serve sampling/createMessage requests
+type SamplingHandler interface {
+	CreateMessage(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error)
+}
+
+// SamplingHandlerFunc adapts a function to the SamplingHandler interface
+type SamplingHandlerFunc func(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error)
+
+func (f SamplingHandlerFunc) CreateMessage(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+	return f(ctx, params)
+}
+
+// HandleCreateMessage decodes and validates raw sampling/createMessage params,
+// with opts applied to the model preferences, routes them to the handler and
+// validates the returned result
+func HandleCreateMessage(ctx context.Context, handler SamplingHandler, rawParams json.RawMessage, opts ...ModelPreferencesOption) (*CreateMessageResult, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("sampling handler cannot be nil")
+	}
+
+	var params CreateMessageParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, fmt.Errorf("decoding create message params: %w", err)
+	}
+
+	if err := params.Validate(opts...); err != nil {
+		return nil, fmt.Errorf("invalid create message params: %w", err)
+	}
+
+	result, err := handler.CreateMessage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("sampling handler returned nil result")
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid create message result: %w", err)
+	}
+
+	return result, nil
+}
+
+// DefaultNonTextTokens is the estimated token cost of an image, audio clip or
+// binary resource
+const DefaultNonTextTokens = 1000
+
+// TokenEstimateOption configures EstimateTokens and FitsBudget
+type TokenEstimateOption func(*tokenEstimateConfig)
+
+type tokenEstimateConfig struct {
+	nonTextTokens int
+}
+
+// WithNonTextTokens overrides DefaultNonTextTokens
+func WithNonTextTokens(tokens int) TokenEstimateOption {
+	return func(c *tokenEstimateConfig) {
+		c.nonTextTokens = tokens
+	}
+}
+
+// EstimateTokens roughly estimates the token count of messages: one token per
+// four characters of text, and a fixed cost for every non-text block. It is a
+// heuristic for rejecting obviously oversized requests, not a tokenizer.
+func EstimateTokens(messages []SamplingMessage, opts ...TokenEstimateOption) int {
+	cfg := tokenEstimateConfig{nonTextTokens: DefaultNonTextTokens}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	total := 0
+	for _, m := range messages {
+		total += estimateContentTokens(m.Content, cfg)
+	}
+	return total
+}
+
+func estimateContentTokens(c Content, cfg tokenEstimateConfig) int {
+	switch {
+	case c.Type == ContentTypeText && c.TextContent != nil:
+		return estimateTextTokens(c.TextContent.Text)
+	case c.Type == ContentTypeResource && c.ResourceContent != nil && c.ResourceContent.Text != nil:
+		return estimateTextTokens(*c.ResourceContent.Text)
+	default:
+		return cfg.nonTextTokens
+	}
+}
+
+func estimateTextTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// FitsBudget reports whether the estimated size of the messages and system
+// prompt stays within MaxTokens
+func (p *CreateMessageParams) FitsBudget(opts ...TokenEstimateOption) bool {
+	estimate := EstimateTokens(p.Messages, opts...)
+	if p.SystemPrompt != nil {
+		estimate += estimateTextTokens(*p.SystemPrompt)
+	}
+	return estimate <= p.MaxTokens
+}
+
 /* Usage Example:
 func ExampleMessage() {
     // Create a simple text message
@@ -162,6 +374,26 @@ func ExampleMessage() {
     if err := createParams.Validate(); err != nil {
         log.Fatal(err)
     }
+    if !createParams.FitsBudget(WithNonTextTokens(1500)) {
+        log.Fatal("prompt is too large for the token budget")
+    }
+
+    // Serve incoming sampling requests on the client side
+    handler := SamplingHandlerFunc(func(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+        return &CreateMessageResult{
+            Role:       RoleAssistant,
+            Content:    *NewTextContent("Go is a programming language.", nil),
+            Model:      "claude-3-5-sonnet",
+            StopReason: ptr(StopReasonEndTurn),
+        }, nil
+    })
+
+    // rawParams is the "params" member of an incoming sampling/createMessage
+    // request; this client accepts up to 20 model hints
+    result, err := HandleCreateMessage(ctx, handler, rawParams, WithMaxModelHints(20))
+    if err != nil {
+        log.Fatal(err)
+    }
 }
 
 // Helper function for string pointers
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestHandleCreateMessageModelPreferencesOptions(t *testing.T) {
+	hints := make([]ModelHint, DefaultMaxModelHints+2)
+	for i := range hints {
+		name := fmt.Sprintf("model-%d", i)
+		hints[i] = ModelHint{Name: &name}
+	}
+	params := CreateMessageParams{
+		Messages:         []SamplingMessage{{Role: RoleUser, Content: *NewTextContent("hi", nil)}},
+		ModelPreferences: &ModelPreferences{Hints: hints},
+		MaxTokens:        10,
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := SamplingHandlerFunc(func(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+		return &CreateMessageResult{Role: RoleAssistant, Content: *NewTextContent("hello", nil), Model: "m"}, nil
+	})
+
+	if _, err := HandleCreateMessage(context.Background(), handler, raw); err == nil {
+		t.Error("expected the default hint limit to reject the params")
+	}
+	if _, err := HandleCreateMessage(context.Background(), handler, raw, WithMaxModelHints(len(hints))); err != nil {
+		t.Errorf("with a raised hint limit: %v", err)
+	}
+}
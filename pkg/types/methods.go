@@ -0,0 +1,190 @@
+package types
+
+// Method names not declared next to their request types
+const (
+	MethodComplete       = "completion/complete"
+	MethodSetLevel       = "logging/setLevel"
+	MethodLoggingMessage = "notifications/message"
+	MethodProgress       = "notifications/progress"
+	MethodListResources  = "resources/list"
+	MethodReadResource   = "resources/read"
+	MethodListPrompts    = "prompts/list"
+	MethodGetPrompt      = "prompts/get"
+	MethodListTools      = "tools/list"
+)
+
+// PaginatedParams are the params of list requests that support cursors
+type PaginatedParams struct {
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+// MethodDirection tells which side sends a method
+type MethodDirection string
+
+const (
+	ClientToServer MethodDirection = "clientToServer"
+	ServerToClient MethodDirection = "serverToClient"
+	Bidirectional  MethodDirection = "bidirectional"
+)
+
+// MethodSpec describes the wire shape of a method
+type MethodSpec struct {
+	Method       string
+	Direction    MethodDirection
+	Notification bool
+	// NewParams returns a pointer to a fresh params value to decode into,
+	// or is nil when the method takes no params
+	NewParams func() interface{}
+	// NewResult returns a pointer to a fresh result value to decode into,
+	// or is nil for notifications and methods with an empty result
+	NewResult func() interface{}
+}
+
+var methodSpecs = map[string]MethodSpec{
+	MethodInitialize: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &InitializeParams{} },
+		NewResult: func() interface{} { return &InitializeResult{} },
+	},
+	MethodInitialized: {
+		Direction:    ClientToServer,
+		Notification: true,
+		NewParams:    func() interface{} { return &InitializedParams{} },
+	},
+	MethodPing: {
+		Direction: Bidirectional,
+	},
+	MethodListResources: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &PaginatedParams{} },
+		NewResult: func() interface{} { return &ListResourcesResult{} },
+	},
+	MethodListResourceTemplates: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &ListResourceTemplatesParams{} },
+		NewResult: func() interface{} { return &ListResourceTemplatesResult{} },
+	},
+	MethodReadResource: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &ReadResourceRequest{} },
+		NewResult: func() interface{} { return &ReadResourceResult{} },
+	},
+	MethodSubscribe: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &SubscribeParams{} },
+	},
+	MethodUnsubscribe: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &SubscribeParams{} },
+	},
+	MethodListPrompts: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &PaginatedParams{} },
+		NewResult: func() interface{} { return &ListPromptsResult{} },
+	},
+	MethodGetPrompt: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &GetPromptRequest{} },
+		NewResult: func() interface{} { return &GetPromptResult{} },
+	},
+	MethodListTools: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &PaginatedParams{} },
+		NewResult: func() interface{} { return &ListToolsResult{} },
+	},
+	MethodCallTool: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &CallToolParams{} },
+		NewResult: func() interface{} { return &CallToolResult{} },
+	},
+	MethodComplete: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &CompleteParams{} },
+		NewResult: func() interface{} { return &CompleteResult{} },
+	},
+	MethodSetLevel: {
+		Direction: ClientToServer,
+		NewParams: func() interface{} { return &SetLevelParams{} },
+	},
+	MethodCreateMessage: {
+		Direction: ServerToClient,
+		NewParams: func() interface{} { return &CreateMessageParams{} },
+		NewResult: func() interface{} { return &CreateMessageResult{} },
+	},
+	MethodElicit: {
+		Direction: ServerToClient,
+		NewParams: func() interface{} { return &ElicitParams{} },
+		NewResult: func() interface{} { return &ElicitResult{} },
+	},
+	MethodLoggingMessage: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &LoggingMessageParams{} },
+	},
+	MethodProgress: {
+		Direction:    Bidirectional,
+		Notification: true,
+		NewParams:    func() interface{} { return &ProgressParams{} },
+	},
+	MethodResourceUpdated: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &ResourceUpdatedParams{} },
+	},
+	MethodCancelled: {
+		Direction:    Bidirectional,
+		Notification: true,
+		NewParams:    func() interface{} { return &CancelledParams{} },
+	},
+	MethodToolListChanged: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &ListChangedParams{} },
+	},
+	MethodResourceListChanged: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &ListChangedParams{} },
+	},
+	MethodPromptListChanged: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &ListChangedParams{} },
+	},
+	MethodPartialToolResult: {
+		Direction:    ServerToClient,
+		Notification: true,
+		NewParams:    func() interface{} { return &PartialCallToolResult{} },
+	},
+}
+
+// MethodInfo looks up the spec of a known method
+func MethodInfo(method string) (MethodSpec, bool) {
+	spec, ok := methodSpecs[method]
+	if !ok {
+		return MethodSpec{}, false
+	}
+	spec.Method = method
+	return spec, true
+}
+
+/* Usage Example:
+func ExampleMethodInfo(req JSONRPCRequest) {
+    spec, ok := MethodInfo(req.Method)
+    if !ok || spec.Notification {
+        // respond with ErrMethodNotFound
+        return
+    }
+
+    params := spec.NewParams()
+    if err := json.Unmarshal(req.Params, params); err != nil {
+        // respond with ErrInvalidParams
+        return
+    }
+
+    switch p := params.(type) {
+    case *CallToolParams:
+        fmt.Println("calling", p.Name)
+    }
+}
+*/
@@ -0,0 +1,171 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Notification is implemented by every notification type. NotificationMethod
+// returns the method the type is sent under, even if its Method field is unset.
+type Notification interface {
+	NotificationMethod() string
+}
+
+func (*InitializedNotification) NotificationMethod() string         { return MethodInitialized }
+func (*LoggingMessageNotification) NotificationMethod() string      { return MethodLoggingMessage }
+func (*ProgressNotification) NotificationMethod() string            { return MethodProgress }
+func (*CancelledNotification) NotificationMethod() string           { return MethodCancelled }
+func (*PartialToolResultNotification) NotificationMethod() string   { return MethodPartialToolResult }
+func (*ResourceUpdatedNotification) NotificationMethod() string     { return MethodResourceUpdated }
+func (*ToolListChangedNotification) NotificationMethod() string     { return MethodToolListChanged }
+func (*ResourceListChangedNotification) NotificationMethod() string { return MethodResourceListChanged }
+func (*PromptListChangedNotification) NotificationMethod() string   { return MethodPromptListChanged }
+
+const (
+	MethodToolListChanged     = "notifications/tools/list_changed"
+	MethodResourceListChanged = "notifications/resources/list_changed"
+	MethodPromptListChanged   = "notifications/prompts/list_changed"
+)
+
+// ListChangedParams are the params of the list-changed notifications, which
+// carry nothing but optional metadata
+type ListChangedParams struct {
+	Meta map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// ToolListChangedNotification tells the client to fetch tools/list again
+type ToolListChangedNotification struct {
+	Method string            `json:"method"`
+	Params ListChangedParams `json:"params"`
+}
+
+// ResourceListChangedNotification tells the client to fetch resources/list again
+type ResourceListChangedNotification struct {
+	Method string            `json:"method"`
+	Params ListChangedParams `json:"params"`
+}
+
+// PromptListChangedNotification tells the client to fetch prompts/list again
+type PromptListChangedNotification struct {
+	Method string            `json:"method"`
+	Params ListChangedParams `json:"params"`
+}
+
+func NewToolListChangedNotification() *ToolListChangedNotification {
+	return &ToolListChangedNotification{Method: MethodToolListChanged}
+}
+
+func NewResourceListChangedNotification() *ResourceListChangedNotification {
+	return &ResourceListChangedNotification{Method: MethodResourceListChanged}
+}
+
+func NewPromptListChangedNotification() *PromptListChangedNotification {
+	return &PromptListChangedNotification{Method: MethodPromptListChanged}
+}
+
+// EmitterOption configures Emitter
+type EmitterOption func(*Emitter) error
+
+// Emitter wraps notifications in a JSON-RPC envelope and writes them to a
+// transport. It is safe for concurrent use if the transport is.
+type Emitter struct {
+	transport Transport
+	stamper   *SequenceStamper
+}
+
+func NewEmitter(transport Transport, opts ...EmitterOption) (*Emitter, error) {
+	if transport == nil {
+		return nil, fmt.Errorf("transport cannot be nil")
+	}
+
+	e := &Emitter{transport: transport}
+
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, fmt.Errorf("applying emitter option: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// Emitter options
+
+// WithSequenceNumbers stamps every emitted notification with the next
+// sequence number from stamper
+func WithSequenceNumbers(stamper *SequenceStamper) EmitterOption {
+	return func(e *Emitter) error {
+		if stamper == nil {
+			return fmt.Errorf("sequence stamper cannot be nil")
+		}
+		e.stamper = stamper
+		return nil
+	}
+}
+
+// Emit writes the notification to the transport
+func (e *Emitter) Emit(n Notification) error {
+	return e.EmitContext(context.Background(), n)
+}
+
+// EmitContext writes the notification to the transport, passing ctx to the write
+func (e *Emitter) EmitContext(ctx context.Context, n Notification) error {
+	if n == nil {
+		return fmt.Errorf("notification cannot be nil")
+	}
+
+	envelope, err := notificationEnvelope(n)
+	if err != nil {
+		return err
+	}
+	if e.stamper != nil {
+		if err := e.stamper.Stamp(envelope); err != nil {
+			return fmt.Errorf("stamping notification: %w", err)
+		}
+	}
+
+	msg, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("marshaling notification: %w", err)
+	}
+	return e.transport.Write(ctx, msg)
+}
+
+// notificationEnvelope moves the params of a typed notification into a
+// JSON-RPC envelope under the notification's canonical method
+func notificationEnvelope(n Notification) (*JSONRPCNotification, error) {
+	_, params, err := messageFields(n, n.NotificationMethod())
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  n.NotificationMethod(),
+		Params:  params,
+	}, nil
+}
+
+/* Usage Example:
+func ExampleEmitter() {
+    transport, err := NewStdioTransport(os.Stdin, os.Stdout)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    emitter, err := NewEmitter(transport, WithSequenceNumbers(NewSequenceStamper()))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Any notification type can be sent without a type switch
+    progress, _ := NewProgressNotification(1, 50, WithProgressTotal(100))
+    logMsg, _ := NewInfoMessage("indexing started", WithLogger("indexer"))
+    for _, n := range []Notification{progress, logMsg} {
+        if err := emitter.Emit(n); err != nil {
+            log.Print(err)
+        }
+    }
+}
+*/
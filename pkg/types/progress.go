@@ -1,7 +1,12 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
 )
 
 // ProgressToken is used to associate progress notifications with requests
@@ -17,9 +22,10 @@ type ProgressNotification struct {
 }
 
 type ProgressParams struct {
-	ProgressToken ProgressToken `json:"progressToken"`
-	Progress      float64       `json:"progress"`
-	Total         *float64      `json:"total,omitempty"`
+	ProgressToken ProgressToken          `json:"progressToken"`
+	Progress      float64                `json:"progress"`
+	Total         *float64               `json:"total,omitempty"`
+	Meta          map[string]interface{} `json:"_meta,omitempty"`
 }
 
 func NewProgressNotification(token ProgressToken, progress float64, opts ...ProgressNotificationOption) (*ProgressNotification, error) {
@@ -28,7 +34,7 @@ func NewProgressNotification(token ProgressToken, progress float64, opts ...Prog
 	}
 
 	notification := &ProgressNotification{
-		Method: "notifications/progress",
+		Method: MethodProgress,
 		Params: ProgressParams{
 			ProgressToken: token,
 			Progress:      progress,
@@ -59,6 +65,21 @@ func WithProgressTotal(total float64) ProgressNotificationOption {
 	}
 }
 
+// WithProgressMeta sets a _meta entry on the notification, such as the name of
+// the operation reporting progress
+func WithProgressMeta(key string, value interface{}) ProgressNotificationOption {
+	return func(n *ProgressNotification) error {
+		if key == "" {
+			return fmt.Errorf("meta key cannot be empty")
+		}
+		if n.Params.Meta == nil {
+			n.Params.Meta = make(map[string]interface{})
+		}
+		n.Params.Meta[key] = value
+		return nil
+	}
+}
+
 // RequestProgressOption configures progress tracking for requests
 type RequestProgressOption func(*RequestProgressMeta) error
 
@@ -67,6 +88,49 @@ type RequestProgressMeta struct {
 	ProgressToken ProgressToken `json:"progressToken,omitempty"`
 }
 
+// MetaKeyProgressToken is the _meta key a request uses to ask for progress notifications
+const MetaKeyProgressToken = "progressToken"
+
+// ExtractProgressToken reads the progress token from a request's _meta. Tokens may
+// arrive as JSON numbers or as strings holding an integer. ProgressToken is an
+// integer, so other string tokens, which the protocol allows, are rejected and
+// report false, as do missing tokens and values outside the int64 range.
+func ExtractProgressToken(meta map[string]interface{}) (ProgressToken, bool) {
+	raw, ok := meta[MetaKeyProgressToken]
+	if !ok {
+		return 0, false
+	}
+
+	switch v := raw.(type) {
+	case ProgressToken:
+		return v, true
+	case int:
+		return ProgressToken(v), true
+	case int64:
+		return ProgressToken(v), true
+	case float64:
+		// float64(math.MaxInt64) rounds up to 1<<63, which would overflow
+		if v != math.Trunc(v) || v >= 1<<63 || v < -1<<63 {
+			return 0, false
+		}
+		return ProgressToken(v), true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return ProgressToken(n), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return ProgressToken(n), true
+	default:
+		return 0, false
+	}
+}
+
 // Helper functions for creating progress notifications at different completion stages
 
 func NewProgressStart(token ProgressToken) (*ProgressNotification, error) {
@@ -105,6 +169,265 @@ func NewProgressWithItems(token ProgressToken, completed, total int) (*ProgressN
 	)
 }
 
+// ProgressTrackerOption configures ProgressTracker
+type ProgressTrackerOption func(*ProgressTracker) error
+
+// ProgressTracker produces progress notifications for a single token and
+// remembers when progress last moved, so stuck operations can be detected.
+// It is safe for concurrent use.
+type ProgressTracker struct {
+	mu         sync.Mutex
+	token      ProgressToken
+	progress   float64
+	total      *float64
+	meta       map[string]interface{}
+	updated    bool
+	lastUpdate time.Time
+	now        func() time.Time
+}
+
+func NewProgressTracker(token ProgressToken, opts ...ProgressTrackerOption) (*ProgressTracker, error) {
+	t := &ProgressTracker{
+		token: token,
+		now:   time.Now,
+	}
+
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, fmt.Errorf("applying progress tracker option: %w", err)
+		}
+	}
+
+	t.lastUpdate = t.now()
+	return t, nil
+}
+
+// Progress tracker options
+
+func WithTrackerTotal(total float64) ProgressTrackerOption {
+	return func(t *ProgressTracker) error {
+		if total <= 0 {
+			return fmt.Errorf("total must be positive")
+		}
+		t.total = &total
+		return nil
+	}
+}
+
+// WithTrackerMeta sets a _meta entry on every notification the tracker produces
+func WithTrackerMeta(key string, value interface{}) ProgressTrackerOption {
+	return func(t *ProgressTracker) error {
+		if key == "" {
+			return fmt.Errorf("meta key cannot be empty")
+		}
+		if t.meta == nil {
+			t.meta = make(map[string]interface{})
+		}
+		t.meta[key] = value
+		return nil
+	}
+}
+
+// notificationOptions returns the options shared by all of the tracker's notifications
+func (t *ProgressTracker) notificationOptions() []ProgressNotificationOption {
+	var opts []ProgressNotificationOption
+	if t.total != nil {
+		opts = append(opts, WithProgressTotal(*t.total))
+	}
+	for key, value := range t.meta {
+		opts = append(opts, WithProgressMeta(key, value))
+	}
+	return opts
+}
+
+// Update records new progress and returns the notification to send.
+// Progress must increase with every update.
+func (t *ProgressTracker) Update(progress float64) (*ProgressNotification, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.updated && progress <= t.progress {
+		return nil, fmt.Errorf("progress must increase: got %f after %f", progress, t.progress)
+	}
+
+	notification, err := NewProgressNotification(t.token, progress, t.notificationOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	t.progress = progress
+	t.updated = true
+	t.lastUpdate = t.now()
+	return notification, nil
+}
+
+// current returns a notification repeating the last recorded progress
+func (t *ProgressTracker) current() (*ProgressNotification, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return NewProgressNotification(t.token, t.progress, t.notificationOptions()...)
+}
+
+// LastUpdate returns when progress last moved, or when the tracker was created
+func (t *ProgressTracker) LastUpdate() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastUpdate
+}
+
+// StalledAfter reports whether no progress has been made within d
+func (t *ProgressTracker) StalledAfter(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.now().Sub(t.lastUpdate) > d
+}
+
+// CompositeProgress reports the progress of an operation that fans out into
+// concurrent child operations. Each child has a weight and a total; the parent
+// token's progress is the weighted average of the children's completion, as a
+// percentage with a total of 100. A notification is sent whenever that average
+// rises, so progress reported to the client never goes backwards, even when a
+// child added late lowers the average. It is safe for concurrent use.
+type CompositeProgress struct {
+	mu       sync.Mutex
+	token    ProgressToken
+	send     func(*ProgressNotification) error
+	children map[ProgressToken]*progressChild
+	weights  float64 // sum of the children's weights
+	reported float64 // last percentage sent
+}
+
+type progressChild struct {
+	weight   float64
+	total    float64
+	progress float64
+}
+
+// NewCompositeProgress reports the children's combined progress for the parent
+// token through send. send is called with the composite's lock held, so that
+// notifications go out in order, and must not call back into it.
+func NewCompositeProgress(parent ProgressToken, send func(*ProgressNotification) error) (*CompositeProgress, error) {
+	if send == nil {
+		return nil, fmt.Errorf("send function cannot be nil")
+	}
+	return &CompositeProgress{
+		token:    parent,
+		send:     send,
+		children: make(map[ProgressToken]*progressChild),
+	}, nil
+}
+
+// AddChild starts tracking a child operation that is done once its progress
+// reaches total. A child's share of the parent is its weight over the sum of
+// all weights.
+func (c *CompositeProgress) AddChild(child ProgressToken, weight, total float64) error {
+	if weight <= 0 {
+		return fmt.Errorf("weight must be positive")
+	}
+	if total <= 0 {
+		return fmt.Errorf("total must be positive")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.children[child]; ok {
+		return fmt.Errorf("child %d already added", child)
+	}
+	c.children[child] = &progressChild{weight: weight, total: total}
+	c.weights += weight
+	return nil
+}
+
+// Update records a child's progress, capped at its total, and sends the
+// parent's progress if it rose
+func (c *CompositeProgress) Update(child ProgressToken, progress float64) error {
+	if progress < 0 {
+		return fmt.Errorf("progress cannot be negative")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.children[child]
+	if !ok {
+		return fmt.Errorf("unknown child %d", child)
+	}
+	ch.progress = math.Min(progress, ch.total)
+	return c.report()
+}
+
+// Complete marks a child as done
+func (c *CompositeProgress) Complete(child ProgressToken) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.children[child]
+	if !ok {
+		return fmt.Errorf("unknown child %d", child)
+	}
+	ch.progress = ch.total
+	return c.report()
+}
+
+// Observe records a progress notification produced for a child, e.g. by its
+// own ProgressTracker. A total in the notification replaces the child's total.
+func (c *CompositeProgress) Observe(n *ProgressNotification) error {
+	if n == nil {
+		return fmt.Errorf("progress notification cannot be nil")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, ok := c.children[n.Params.ProgressToken]
+	if !ok {
+		return fmt.Errorf("unknown child %d", n.Params.ProgressToken)
+	}
+	if n.Params.Total != nil && *n.Params.Total > 0 {
+		ch.total = *n.Params.Total
+	}
+	ch.progress = math.Min(n.Params.Progress, ch.total)
+	return c.report()
+}
+
+// Progress returns the parent's completion as a percentage
+func (c *CompositeProgress) Progress() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.percentage()
+}
+
+func (c *CompositeProgress) percentage() float64 {
+	if c.weights == 0 {
+		return 0
+	}
+	var done float64
+	for _, ch := range c.children {
+		done += ch.weight * ch.progress / ch.total
+	}
+	return math.Min(done/c.weights*100, 100)
+}
+
+// report sends the parent's progress if it rose. Callers hold c.mu.
+func (c *CompositeProgress) report() error {
+	progress := c.percentage()
+	if progress <= c.reported {
+		return nil
+	}
+
+	notification, err := NewProgressNotification(c.token, progress, WithProgressTotal(100))
+	if err != nil {
+		return err
+	}
+	if err := c.send(notification); err != nil {
+		return err
+	}
+	c.reported = progress
+	return nil
+}
+
 /* Usage Example:
 func ExampleProgress() {
     // Simple progress tracking
@@ -138,6 +461,15 @@ func ExampleProgress() {
         log.Fatal(err)
     }
 
+    // Correlation data travels in _meta alongside the progress
+    indexing, err := NewProgressNotification(token, 10,
+        WithProgressTotal(40),
+        WithProgressMeta("operation", "reindex"),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Example of processing a collection with progress updates
     func ProcessItemsWithProgress(items []string, token ProgressToken) error {
         total := len(items)
@@ -180,6 +512,67 @@ func ExampleProgress() {
     }
 }
 
+// Example of a supervisor cancelling a stuck operation
+func ExampleProgressTracker(ctx context.Context, cancel context.CancelFunc) {
+    tracker, err := NewProgressTracker(ProgressToken(7), WithTrackerTotal(10))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    go func() {
+        ticker := time.NewTicker(5 * time.Second)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                if tracker.StalledAfter(30 * time.Second) {
+                    cancel()
+                    return
+                }
+            }
+        }
+    }()
+
+    for i := 1; i <= 10; i++ {
+        // Process item...
+        notification, err := tracker.Update(float64(i))
+        if err != nil {
+            log.Fatal(err)
+        }
+        // Send notification...
+    }
+}
+
+// Example of reporting one request's progress across parallel sub-tasks
+func ExampleCompositeProgress(ctx context.Context, token ProgressToken, shards []Shard) {
+    composite, err := NewCompositeProgress(token, func(n *ProgressNotification) error {
+        return emitter.EmitContext(ctx, n)
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // The large shard counts for more of the overall progress
+    for i, shard := range shards {
+        composite.AddChild(ProgressToken(i), float64(shard.Size), float64(len(shard.Items)))
+    }
+
+    var wg sync.WaitGroup
+    for i, shard := range shards {
+        wg.Add(1)
+        go func(child ProgressToken, shard Shard) {
+            defer wg.Done()
+            for j, item := range shard.Items {
+                process(item)
+                composite.Update(child, float64(j+1))
+            }
+        }(ProgressToken(i), shard)
+    }
+    wg.Wait()
+}
+
 // Example of using progress with a request
 func ExampleRequestWithProgress() {
     type LongRunningRequest struct {
@@ -211,9 +604,17 @@ func ExampleRequestWithProgress() {
         {4, 4},   // 100%
     }
 
+    // Servers read the token back from the incoming request's _meta
+    token, ok := ExtractProgressToken(map[string]interface{}{
+        "progressToken": float64(123), // as decoded by encoding/json
+    })
+    if !ok {
+        return // the client did not ask for progress
+    }
+
     for _, p := range progress {
         notification, err := NewProgressWithItems(
-            req.Meta.ProgressToken,
+            token,
             p.Completed,
             p.Total,
         )
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestExtractProgressToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   interface{}
+		want  ProgressToken
+		valid bool
+	}{
+		{"float", float64(7), 7, true},
+		{"json number", json.Number("42"), 42, true},
+		{"integer string", "12", 12, true},
+		{"smallest int64", float64(math.MinInt64), math.MinInt64, true},
+		{"fraction", 1.5, 0, false},
+		{"two to the 63", math.Pow(2, 63), 0, false},
+		{"non-integer string", "abc-123", 0, false},
+		{"bool", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractProgressToken(map[string]interface{}{MetaKeyProgressToken: tt.raw})
+			if ok != tt.valid || got != tt.want {
+				t.Errorf("ExtractProgressToken(%#v) = %d, %v; want %d, %v", tt.raw, got, ok, tt.want, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCompositeProgressConcurrentUpdates(t *testing.T) {
+	var mu sync.Mutex
+	var reported []float64
+	composite, err := NewCompositeProgress(1, func(n *ProgressNotification) error {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, n.Params.Progress)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	children := []struct {
+		token  ProgressToken
+		weight float64
+		total  float64
+	}{
+		{10, 1, 10},
+		{11, 2, 20},
+		{12, 3, 40},
+		{13, 4, 100},
+	}
+	for _, ch := range children {
+		if err := composite.AddChild(ch.token, ch.weight, ch.total); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// run moves every child concurrently, stepping it to the fraction of its
+	// total given by target, with a dip on the way that the parent must not report
+	run := func(target float64) {
+		var wg sync.WaitGroup
+		for _, ch := range children {
+			wg.Add(1)
+			go func(token ProgressToken, total float64) {
+				defer wg.Done()
+				for step := 1; step <= 20; step++ {
+					progress := total * target * float64(step) / 20
+					if step == 10 {
+						progress /= 2
+					}
+					if err := composite.Update(token, progress); err != nil {
+						t.Error(err)
+					}
+					_ = composite.Progress()
+				}
+			}(ch.token, ch.total)
+		}
+		wg.Wait()
+	}
+
+	run(0.5)
+	if got := composite.Progress(); math.Abs(got-50) > 1e-9 {
+		t.Errorf("progress with every child halfway = %v, want 50", got)
+	}
+
+	// weighted: only the heaviest child finishes, the rest stay halfway
+	if err := composite.Complete(13); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := composite.Progress(), (1*0.5+2*0.5+3*0.5+4*1.0)/10*100; math.Abs(got-want) > 1e-9 {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+
+	run(1)
+	if got := composite.Progress(); got != 100 {
+		t.Errorf("final progress = %v, want 100", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) == 0 || reported[len(reported)-1] != 100 {
+		t.Fatalf("reported %v, want it to end at 100", reported)
+	}
+	for i := 1; i < len(reported); i++ {
+		if reported[i] <= reported[i-1] {
+			t.Fatalf("reported progress went from %v to %v", reported[i-1], reported[i])
+		}
+	}
+}
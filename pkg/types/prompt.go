@@ -108,6 +108,35 @@ type ListPromptsResult struct {
     Prompts    []Prompt `json:"prompts"`
 }
 
+// PromptMessagesToSampling converts prompt messages into sampling messages, e.g.
+// to pass a GetPromptResult on to sampling/createMessage. The two types share a
+// shape but stay distinct because the spec allows embedded resources only in
+// prompts; such content is copied unchanged and should be checked before sending.
+func PromptMessagesToSampling(msgs []PromptMessage) []SamplingMessage {
+    if msgs == nil {
+        return nil
+    }
+
+    out := make([]SamplingMessage, len(msgs))
+    for i, m := range msgs {
+        out[i] = SamplingMessage{Role: m.Role, Content: m.Content}
+    }
+    return out
+}
+
+// SamplingMessagesToPrompt converts sampling messages into prompt messages
+func SamplingMessagesToPrompt(msgs []SamplingMessage) []PromptMessage {
+    if msgs == nil {
+        return nil
+    }
+
+    out := make([]PromptMessage, len(msgs))
+    for i, m := range msgs {
+        out[i] = PromptMessage{Role: m.Role, Content: m.Content}
+    }
+    return out
+}
+
 /* Usage Example:
 func ExamplePrompt() {
     // Create a new prompt with arguments
@@ -157,5 +186,11 @@ func ExamplePrompt() {
     listResult := ListPromptsResult{
         Prompts: []Prompt{*prompt},
     }
+
+    // Feed the prompt into a sampling request
+    params := CreateMessageParams{
+        Messages:  PromptMessagesToSampling(result.Messages),
+        MaxTokens: 1024,
+    }
 }
 */
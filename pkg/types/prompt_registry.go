@@ -0,0 +1,126 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PromptHandler is implemented by servers to render a prompt's messages
+type PromptHandler interface {
+	GetPrompt(ctx context.Context, args map[string]string) (*GetPromptResult, error)
+}
+
+// PromptHandlerFunc adapts a function to the PromptHandler interface
+type PromptHandlerFunc func(ctx context.Context, args map[string]string) (*GetPromptResult, error)
+
+func (f PromptHandlerFunc) GetPrompt(ctx context.Context, args map[string]string) (*GetPromptResult, error) {
+	return f(ctx, args)
+}
+
+// PromptRegistry routes prompts/get requests to registered handlers after
+// checking that every required argument was supplied. It is safe for
+// concurrent use.
+type PromptRegistry struct {
+	mu      sync.RWMutex
+	prompts map[string]registeredPrompt
+}
+
+type registeredPrompt struct {
+	prompt  Prompt
+	handler PromptHandler
+}
+
+func NewPromptRegistry() *PromptRegistry {
+	return &PromptRegistry{prompts: make(map[string]registeredPrompt)}
+}
+
+// Register adds a prompt and its handler, replacing any prompt with the same name
+func (r *PromptRegistry) Register(prompt Prompt, handler PromptHandler) error {
+	if prompt.Name == "" {
+		return fmt.Errorf("prompt name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("prompt handler cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts[prompt.Name] = registeredPrompt{prompt: prompt, handler: handler}
+	return nil
+}
+
+// Prompts returns the registered prompts ordered by name
+func (r *PromptRegistry) Prompts() []Prompt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prompts := make([]Prompt, 0, len(r.prompts))
+	for _, rp := range r.prompts {
+		prompts = append(prompts, rp.prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts
+}
+
+// Get handles a prompts/get request. Unknown prompts and missing required
+// arguments yield an invalid params error.
+func (r *PromptRegistry) Get(ctx context.Context, req *GetPromptRequest) (*GetPromptResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("get prompt request cannot be nil")
+	}
+
+	r.mu.RLock()
+	rp, ok := r.prompts[req.Name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, &ErrorInfo{
+			Code:    ErrInvalidParams,
+			Message: fmt.Sprintf("Unknown prompt: %s", req.Name),
+		}
+	}
+
+	var failures []ValidationFailure
+	for _, arg := range rp.prompt.Arguments {
+		if arg.Required == nil || !*arg.Required {
+			continue
+		}
+		if _, ok := req.Arguments[arg.Name]; !ok {
+			failures = append(failures, ValidationFailure{
+				Field: arg.Name,
+				Path:  joinPointer("/arguments", arg.Name),
+				Error: "required argument is missing",
+			})
+		}
+	}
+	if len(failures) > 0 {
+		return nil, NewValidationError(failures)
+	}
+
+	return rp.handler.GetPrompt(ctx, req.Arguments)
+}
+
+/* Usage Example:
+func ExamplePromptRegistry() {
+    prompt, err := NewPrompt("summarize",
+        WithPromptDescription("Summarize a document"),
+        WithPromptArgument("uri", WithArgumentRequired(true)),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    registry := NewPromptRegistry()
+    registry.Register(*prompt, PromptHandlerFunc(func(ctx context.Context, args map[string]string) (*GetPromptResult, error) {
+        text := "Summarize the document at " + args["uri"]
+        return &GetPromptResult{
+            Messages: []PromptMessage{{Role: RoleUser, Content: *NewTextContent(text, nil)}},
+        }, nil
+    }))
+
+    // Fails with a validation error because "uri" is required
+    _, err = registry.Get(ctx, &GetPromptRequest{Name: "summarize"})
+}
+*/
@@ -1,7 +1,19 @@
 package types
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // ResourceOption configures a Resource
@@ -14,6 +26,11 @@ type Resource struct {
 	Description *string      `json:"description,omitempty"`
 	MimeType    *string      `json:"mimeType,omitempty"`
 	Annotations *Annotations `json:"annotations,omitempty"`
+	// ReadOnly advertises that the resource cannot be modified
+	ReadOnly *bool                  `json:"readOnly,omitempty"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+
+	annotationsFunc func() (*Annotations, error)
 }
 
 func NewResource(uri, name string, opts ...ResourceOption) (*Resource, error) {
@@ -56,11 +73,103 @@ func WithResourceMimeType(mimeType string) ResourceOption {
 
 func WithResourceAnnotations(annotations *Annotations) ResourceOption {
 	return func(r *Resource) error {
+		if err := annotations.Validate(); err != nil {
+			return fmt.Errorf("invalid annotations: %w", err)
+		}
 		r.Annotations = annotations
 		return nil
 	}
 }
 
+func WithResourceReadOnly(readOnly bool) ResourceOption {
+	return func(r *Resource) error {
+		r.ReadOnly = &readOnly
+		return nil
+	}
+}
+
+// WithResourceAnnotationsFunc defers computing annotations until ResolveAnnotations
+// is called, typically when the resource is listed or read. Useful for servers with
+// many resources whose annotations are expensive to compute.
+func WithResourceAnnotationsFunc(fn func() (*Annotations, error)) ResourceOption {
+	return func(r *Resource) error {
+		if fn == nil {
+			return fmt.Errorf("annotations func cannot be nil")
+		}
+		r.annotationsFunc = fn
+		return nil
+	}
+}
+
+// ResolveAnnotations runs the lazy annotations producer, if any, validates the
+// result and stores it in Annotations. The producer runs on every call so values
+// such as timestamps stay fresh.
+func (r *Resource) ResolveAnnotations() error {
+	if r.annotationsFunc == nil {
+		return nil
+	}
+
+	annotations, err := r.annotationsFunc()
+	if err != nil {
+		return fmt.Errorf("computing annotations for %s: %w", r.URI, err)
+	}
+	if err := annotations.Validate(); err != nil {
+		return fmt.Errorf("invalid annotations for %s: %w", r.URI, err)
+	}
+
+	r.Annotations = annotations
+	return nil
+}
+
+// WithResourceMeta sets a server-specific _meta entry on the resource
+func WithResourceMeta(key string, value interface{}) ResourceOption {
+	return func(r *Resource) error {
+		if key == "" {
+			return fmt.Errorf("meta key cannot be empty")
+		}
+		if r.Meta == nil {
+			r.Meta = make(map[string]interface{})
+		}
+		r.Meta[key] = value
+		return nil
+	}
+}
+
+// Validate checks that the resource has a name and an absolute URI and that its
+// annotations are valid
+func (r *Resource) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("resource name cannot be empty")
+	}
+	if r.URI == "" {
+		return fmt.Errorf("resource URI cannot be empty")
+	}
+	u, err := url.Parse(r.URI)
+	if err != nil {
+		return fmt.Errorf("invalid resource URI: %w", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("resource URI must be absolute, got %q", r.URI)
+	}
+	if err := r.Annotations.Validate(); err != nil {
+		return fmt.Errorf("invalid annotations: %w", err)
+	}
+	return nil
+}
+
+// IsReadOnly reports whether the resource is advertised as read-only
+func (r *Resource) IsReadOnly() bool {
+	return r.ReadOnly != nil && *r.ReadOnly
+}
+
+// MarshalJSON omits empty annotations
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type Alias Resource
+	alias := Alias(r)
+	alias.Annotations = nonEmptyAnnotations(alias.Annotations)
+	return json.Marshal(alias)
+}
+
 // ResourceTemplate represents a template for resources
 type ResourceTemplateOption func(*ResourceTemplate) error
 
@@ -70,6 +179,8 @@ type ResourceTemplate struct {
 	Description *string      `json:"description,omitempty"`
 	MimeType    *string      `json:"mimeType,omitempty"`
 	Annotations *Annotations `json:"annotations,omitempty"`
+
+	completions map[string]CompletionProvider
 }
 
 func NewResourceTemplate(name, uriTemplate string, opts ...ResourceTemplateOption) (*ResourceTemplate, error) {
@@ -112,11 +223,70 @@ func WithTemplateMimeType(mimeType string) ResourceTemplateOption {
 
 func WithTemplateAnnotations(annotations *Annotations) ResourceTemplateOption {
 	return func(rt *ResourceTemplate) error {
+		if err := annotations.Validate(); err != nil {
+			return fmt.Errorf("invalid annotations: %w", err)
+		}
 		rt.Annotations = annotations
 		return nil
 	}
 }
 
+// WithTemplateCompletion sets the completion source for one variable of the URI
+// template. Register the template with CompletionRegistry.RegisterTemplateCompletions
+// to serve completion/complete requests for it.
+func WithTemplateCompletion(variable string, provider CompletionProvider) ResourceTemplateOption {
+	return func(rt *ResourceTemplate) error {
+		if provider == nil {
+			return fmt.Errorf("completion provider cannot be nil")
+		}
+		if !containsString(templateVariables(rt.URITemplate), variable) {
+			return fmt.Errorf("variable %s is not defined in URI template %s", variable, rt.URITemplate)
+		}
+		if rt.completions == nil {
+			rt.completions = make(map[string]CompletionProvider)
+		}
+		rt.completions[variable] = provider
+		return nil
+	}
+}
+
+// MarshalJSON omits empty annotations
+func (rt ResourceTemplate) MarshalJSON() ([]byte, error) {
+	type Alias ResourceTemplate
+	alias := Alias(rt)
+	alias.Annotations = nonEmptyAnnotations(alias.Annotations)
+	return json.Marshal(alias)
+}
+
+// templateVariables returns the variable names of an RFC 6570 URI template in
+// order of appearance, without operators or modifiers
+func templateVariables(uriTemplate string) []string {
+	var names []string
+	rest := uriTemplate
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return names
+		}
+
+		expr := rest[start+1 : start+end]
+		expr = strings.TrimLeft(expr, "+#./;?&")
+		for _, spec := range strings.Split(expr, ",") {
+			if i := strings.IndexAny(spec, ":*"); i >= 0 {
+				spec = spec[:i]
+			}
+			if spec != "" && !containsString(names, spec) {
+				names = append(names, spec)
+			}
+		}
+		rest = rest[start+end+1:]
+	}
+}
+
 // ResourceContentOption configures ResourceContent
 type ResourceContentOption func(*ResourceContent) error
 
@@ -127,8 +297,30 @@ type ResourceContent struct {
 	Blob        *string      `json:"blob,omitempty"` // base64 encoded
 	MimeType    *string      `json:"mimeType,omitempty"`
 	Annotations *Annotations `json:"annotations,omitempty"`
+	Encoding    *string      `json:"encoding,omitempty"` // compression applied to blob
+	Checksum    *string      `json:"checksum,omitempty"` // "sha256:<hex>" of the uncompressed bytes
+	// EncodedText is set when the compressed blob was text, so that
+	// DecompressContent restores text rather than a blob
+	EncodedText *bool `json:"encodedText,omitempty"`
+	// Stream is set instead of Blob when the bytes were sent as progress
+	// notifications; see StreamResourceContent
+	Stream *ResourceStream `json:"stream,omitempty"`
+	// Range is set when only part of the text was read; see ContentRange
+	Range *ContentRange `json:"range,omitempty"`
 }
 
+// ContentEncodingGzip marks a blob holding gzip-compressed bytes
+const ContentEncodingGzip = "gzip"
+
+// ChecksumPrefixSHA256 prefixes a hex-encoded SHA-256 content checksum
+const ChecksumPrefixSHA256 = "sha256:"
+
+// ErrChecksumMismatch is returned when content does not match its checksum
+var ErrChecksumMismatch = errors.New("content checksum mismatch")
+
+// ErrContentTooLarge is returned when decompressed content exceeds the size limit
+var ErrContentTooLarge = errors.New("content exceeds size limit")
+
 func NewResourceContent(uri string, opts ...ResourceContentOption) (*ResourceContent, error) {
 	if uri == "" {
 		return nil, fmt.Errorf("resource URI cannot be empty")
@@ -149,6 +341,10 @@ func NewResourceContent(uri string, opts ...ResourceContentOption) (*ResourceCon
 		return nil, fmt.Errorf("exactly one of text or blob must be set")
 	}
 
+	if rc.Encoding != nil && rc.Blob == nil {
+		return nil, fmt.Errorf("encoding requires blob content")
+	}
+
 	return rc, nil
 }
 
@@ -183,19 +379,532 @@ func WithContentMimeType(mimeType string) ResourceContentOption {
 
 func WithContentAnnotations(annotations *Annotations) ResourceContentOption {
 	return func(rc *ResourceContent) error {
+		if err := annotations.Validate(); err != nil {
+			return fmt.Errorf("invalid annotations: %w", err)
+		}
 		rc.Annotations = annotations
 		return nil
 	}
 }
 
+// WithContentEncoding marks the blob as compressed. Only gzip is supported;
+// use CompressContent to compress existing content instead.
+func WithContentEncoding(encoding string) ResourceContentOption {
+	return func(rc *ResourceContent) error {
+		if encoding != ContentEncodingGzip {
+			return fmt.Errorf("unsupported content encoding: %s", encoding)
+		}
+		rc.Encoding = &encoding
+		return nil
+	}
+}
+
+// WithContentChecksum attaches a checksum in "sha256:<hex>" form, usually one
+// computed by ComputeChecksum
+func WithContentChecksum(checksum string) ResourceContentOption {
+	return func(rc *ResourceContent) error {
+		if err := validateChecksum(checksum); err != nil {
+			return err
+		}
+		rc.Checksum = &checksum
+		return nil
+	}
+}
+
+// WithContentSource records the upstream source the content was assembled from
+func WithContentSource(uri string) ResourceContentOption {
+	return func(rc *ResourceContent) error {
+		if err := validateSourceURI(uri); err != nil {
+			return err
+		}
+		// copy so annotations shared with other content are left untouched
+		annotations := Annotations{}
+		if rc.Annotations != nil {
+			annotations = *rc.Annotations
+		}
+		annotations.Source = &uri
+		rc.Annotations = &annotations
+		return nil
+	}
+}
+
+// ByteSize returns the size of the content in bytes: the UTF-8 length of text,
+// or the decoded length of a base64 blob. Blobs are decoded as a stream, so the
+// decoded bytes are never held in memory.
+func (rc *ResourceContent) ByteSize() (int64, error) {
+	switch {
+	case rc.Text != nil:
+		return int64(len(*rc.Text)), nil
+	case rc.Blob != nil:
+		decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(*rc.Blob))
+		n, err := io.Copy(io.Discard, decoder)
+		if err != nil {
+			return 0, fmt.Errorf("decoding blob: %w", err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("resource content has neither text nor blob")
+	}
+}
+
+// ComputeChecksum returns the SHA-256 checksum of the content's bytes in
+// "sha256:<hex>" form: the UTF-8 text, or the decoded blob. Compressed blobs are
+// hashed after decompression, so compressing content keeps its checksum valid.
+func ComputeChecksum(rc *ResourceContent) (string, error) {
+	var r io.Reader
+	switch {
+	case rc.Text != nil:
+		r = strings.NewReader(*rc.Text)
+	case rc.Blob != nil:
+		r = base64.NewDecoder(base64.StdEncoding, strings.NewReader(*rc.Blob))
+	default:
+		return "", fmt.Errorf("resource content has neither text nor blob")
+	}
+
+	if rc.Encoding != nil {
+		if *rc.Encoding != ContentEncodingGzip {
+			return "", fmt.Errorf("unsupported content encoding: %s", *rc.Encoding)
+		}
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return "", fmt.Errorf("reading gzip header: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("hashing content: %w", err)
+	}
+	return ChecksumPrefixSHA256 + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyChecksum recomputes the checksum and compares it with the attached one.
+// Content without a checksum passes.
+func (rc *ResourceContent) VerifyChecksum() error {
+	if rc.Checksum == nil {
+		return nil
+	}
+	sum, err := ComputeChecksum(rc)
+	if err != nil {
+		return err
+	}
+	if sum != *rc.Checksum {
+		return fmt.Errorf("%w: %s: got %s, want %s", ErrChecksumMismatch, rc.URI, sum, *rc.Checksum)
+	}
+	return nil
+}
+
+// MarshalJSON omits empty annotations
+func (rc ResourceContent) MarshalJSON() ([]byte, error) {
+	type Alias ResourceContent
+	alias := Alias(rc)
+	alias.Annotations = nonEmptyAnnotations(alias.Annotations)
+	return json.Marshal(alias)
+}
+
+// UnmarshalJSON rejects checksums that are not in "sha256:<hex>" form and
+// encodings other than gzip or not applied to a blob
+func (rc *ResourceContent) UnmarshalJSON(data []byte) error {
+	type Alias ResourceContent
+	var aux Alias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Encoding != nil {
+		if *aux.Encoding != ContentEncodingGzip {
+			return fmt.Errorf("unsupported content encoding: %s", *aux.Encoding)
+		}
+		if aux.Blob == nil || aux.Text != nil {
+			return fmt.Errorf("encoded content must be a blob")
+		}
+	} else if aux.EncodedText != nil {
+		return fmt.Errorf("encodedText requires an encoding")
+	}
+	if aux.Checksum != nil {
+		if err := validateChecksum(*aux.Checksum); err != nil {
+			return err
+		}
+	}
+	*rc = ResourceContent(aux)
+	return nil
+}
+
+func validateChecksum(checksum string) error {
+	digest, ok := strings.CutPrefix(checksum, ChecksumPrefixSHA256)
+	if !ok || len(digest) != sha256.Size*2 {
+		return fmt.Errorf("invalid checksum %q: must be %s followed by %d hex digits", checksum, ChecksumPrefixSHA256, sha256.Size*2)
+	}
+	for _, c := range digest {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return fmt.Errorf("invalid checksum %q: digest must be lowercase hex", checksum)
+		}
+	}
+	return nil
+}
+
+// CompressContent returns a copy of rc with its text or blob gzip-compressed
+// into a base64 blob and Encoding set, and EncodedText set for text. MimeType
+// keeps describing the uncompressed content.
+func CompressContent(rc *ResourceContent) (*ResourceContent, error) {
+	if rc.Encoding != nil {
+		return nil, fmt.Errorf("content is already encoded as %s", *rc.Encoding)
+	}
+
+	var raw []byte
+	switch {
+	case rc.Text != nil:
+		raw = []byte(*rc.Text)
+	case rc.Blob != nil:
+		decoded, err := base64.StdEncoding.DecodeString(*rc.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("decoding blob: %w", err)
+		}
+		raw = decoded
+	default:
+		return nil, fmt.Errorf("resource content has neither text nor blob")
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("compressing content: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing content: %w", err)
+	}
+
+	blob := base64.StdEncoding.EncodeToString(buf.Bytes())
+	encoding := ContentEncodingGzip
+	compressed := *rc
+	compressed.Text = nil
+	compressed.Blob = &blob
+	compressed.Encoding = &encoding
+	if rc.Text != nil {
+		encodedText := true
+		compressed.EncodedText = &encodedText
+	}
+	return &compressed, nil
+}
+
+// DecompressContent reverses CompressContent, reading at most maxBytes of
+// decompressed data. Content without an Encoding is returned as a copy. The
+// result is text when EncodedText is set, in which case the bytes must be
+// valid UTF-8, and a base64 blob otherwise.
+func DecompressContent(rc *ResourceContent, maxBytes int64) (*ResourceContent, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("max bytes must be positive")
+	}
+
+	out := *rc
+	if rc.Encoding == nil {
+		return &out, nil
+	}
+	if *rc.Encoding != ContentEncodingGzip {
+		return nil, fmt.Errorf("unsupported content encoding: %s", *rc.Encoding)
+	}
+	if rc.Text != nil || rc.Blob == nil {
+		return nil, fmt.Errorf("encoded content must be a blob")
+	}
+
+	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(*rc.Blob))
+	zr, err := gzip.NewReader(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip header: %w", err)
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(zr, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing content: %w", err)
+	}
+	if int64(len(raw)) > maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrContentTooLarge, maxBytes)
+	}
+
+	out.Encoding = nil
+	out.EncodedText = nil
+	if rc.EncodedText != nil && *rc.EncodedText {
+		if !utf8.Valid(raw) {
+			return nil, fmt.Errorf("decompressed text is not valid UTF-8")
+		}
+		text := string(raw)
+		out.Text = &text
+		out.Blob = nil
+	} else {
+		blob := base64.StdEncoding.EncodeToString(raw)
+		out.Blob = &blob
+	}
+	return &out, nil
+}
+
+func isTextMimeType(mimeType *string) bool {
+	if mimeType == nil {
+		return true
+	}
+
+	mt := strings.ToLower(strings.TrimSpace(strings.SplitN(*mimeType, ";", 2)[0]))
+	switch {
+	case strings.HasPrefix(mt, "text/"):
+		return true
+	case mt == "application/json", mt == "application/xml", mt == "application/yaml", mt == "application/javascript":
+		return true
+	case strings.HasSuffix(mt, "+json"), strings.HasSuffix(mt, "+xml"):
+		return true
+	}
+	return false
+}
+
 // Request/Response types
 
+// ReadResourceRequestOption configures ReadResourceRequest
+type ReadResourceRequestOption func(*ReadResourceRequest) error
+
 type ReadResourceRequest struct {
 	URI string `json:"uri"`
+	// Cursor requests the next page of a paginated read
+	Cursor *string `json:"cursor,omitempty"`
+	// Range requests only part of a text resource
+	Range *ContentRange `json:"range,omitempty"`
+	// Meta may carry a progress token, which lets the server stream large blobs
+	Meta map[string]interface{} `json:"_meta,omitempty"`
+}
+
+func NewReadResourceRequest(uri string, opts ...ReadResourceRequestOption) (*ReadResourceRequest, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("resource URI cannot be empty")
+	}
+
+	req := &ReadResourceRequest{URI: uri}
+
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, fmt.Errorf("applying read resource request option: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// WithResourceContentRange asks for only part of a text resource
+func WithResourceContentRange(r ContentRange) ReadResourceRequestOption {
+	return func(req *ReadResourceRequest) error {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		req.Range = &r
+		return nil
+	}
+}
+
+// RangeUnit is what a ContentRange counts
+type RangeUnit string
+
+const (
+	RangeUnitBytes RangeUnit = "bytes"
+	RangeUnitLines RangeUnit = "lines"
+)
+
+// ContentRange selects the window [Start, End) of a text resource, counted
+// in bytes of its UTF-8 text or in lines, from zero. Byte ranges are widened
+// to whole runes; a line includes its trailing newline. An End past the end
+// of the text is clamped. In a response, the content's Range holds the window
+// actually returned and Total the size of the whole text in the same unit.
+type ContentRange struct {
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+	Unit  RangeUnit `json:"unit,omitempty"` // defaults to bytes
+	Total *int      `json:"total,omitempty"`
+}
+
+// Validate checks that the range is non-empty and its unit is known
+func (r ContentRange) Validate() error {
+	if r.Start < 0 {
+		return fmt.Errorf("range start cannot be negative")
+	}
+	if r.End <= r.Start {
+		return fmt.Errorf("range end (%d) must be after start (%d)", r.End, r.Start)
+	}
+	switch r.Unit {
+	case "", RangeUnitBytes, RangeUnitLines:
+		return nil
+	default:
+		return fmt.Errorf("invalid range unit: %s", r.Unit)
+	}
+}
+
+// SliceText returns the part of text the range selects, along with the range
+// actually covered and the total size of text
+func (r ContentRange) SliceText(text string) (string, ContentRange, error) {
+	if err := r.Validate(); err != nil {
+		return "", ContentRange{}, err
+	}
+
+	var total int
+	var bounds func(i int) int // byte offset of unit i
+	if r.Unit == RangeUnitLines {
+		lines := []int{0}
+		for i := 0; i < len(text); i++ {
+			if text[i] == '\n' && i+1 < len(text) {
+				lines = append(lines, i+1)
+			}
+		}
+		total = len(lines)
+		if text == "" {
+			total = 0
+		}
+		bounds = func(i int) int {
+			if i >= len(lines) {
+				return len(text)
+			}
+			return lines[i]
+		}
+	} else {
+		total = len(text)
+		bounds = func(i int) int { return i }
+	}
+	if r.Start >= total {
+		return "", ContentRange{}, fmt.Errorf("range start %d is past the end (%d %s)", r.Start, total, r.unit())
+	}
+
+	start, end := r.Start, min(r.End, total)
+	if r.unit() == RangeUnitBytes {
+		// widen to whole runes; start < total, so text[start] exists
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < total && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+
+	covered := ContentRange{Start: start, End: end, Unit: r.unit(), Total: &total}
+	return text[bounds(start):bounds(end)], covered, nil
+}
+
+func (r ContentRange) unit() RangeUnit {
+	if r.Unit == "" {
+		return RangeUnitBytes
+	}
+	return r.Unit
 }
 
 type ReadResourceResult struct {
 	Contents []ResourceContent `json:"contents"`
+	// NextCursor is set when more content blocks remain to be read
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
+// PageResourceContents returns the page of contents starting at cursor, holding
+// at most pageSize blocks, with NextCursor set when more remain. A nil cursor
+// starts at the first block. Clients pass NextCursor back in
+// ReadResourceRequest.Cursor to read the next page of the same URI.
+func PageResourceContents(contents []ResourceContent, cursor *string, pageSize int) (*ReadResourceResult, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive")
+	}
+
+	offset := 0
+	if cursor != nil {
+		var err error
+		offset, err = decodeOffsetCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		if offset > len(contents) {
+			return nil, fmt.Errorf("cursor is past the end of the contents")
+		}
+	}
+
+	end := offset + pageSize
+	if end > len(contents) {
+		end = len(contents)
+	}
+
+	result := &ReadResourceResult{
+		Contents: contents[offset:end],
+	}
+	if end < len(contents) {
+		next := encodeOffsetCursor(end)
+		result.NextCursor = &next
+	}
+	return result, nil
+}
+
+// encodeOffsetCursor makes an opaque cursor for a position in a list
+func encodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeOffsetCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %q", cursor)
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor: %q", cursor)
+	}
+	return offset, nil
+}
+
+const (
+	MethodResourceUpdated = "notifications/resources/updated"
+	MethodSubscribe       = "resources/subscribe"
+	MethodUnsubscribe     = "resources/unsubscribe"
+)
+
+// SubscribeParams are the params of resources/subscribe and
+// resources/unsubscribe
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedNotificationOption configures ResourceUpdatedNotification
+type ResourceUpdatedNotificationOption func(*ResourceUpdatedNotification) error
+
+// ResourceUpdatedNotification tells a subscribed client that a resource changed
+type ResourceUpdatedNotification struct {
+	Method string                `json:"method"`
+	Params ResourceUpdatedParams `json:"params"`
+}
+
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+	// Patch is a unified diff from DiffResourceContent against the content the
+	// client last read. Without it the client re-reads the resource.
+	Patch *string `json:"patch,omitempty"`
+}
+
+func NewResourceUpdatedNotification(uri string, opts ...ResourceUpdatedNotificationOption) (*ResourceUpdatedNotification, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("resource URI cannot be empty")
+	}
+
+	n := &ResourceUpdatedNotification{
+		Method: MethodResourceUpdated,
+		Params: ResourceUpdatedParams{URI: uri},
+	}
+
+	for _, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, fmt.Errorf("applying resource updated option: %w", err)
+		}
+	}
+
+	return n, nil
+}
+
+// WithResourcePatch attaches a patch so the client can update its copy in place
+func WithResourcePatch(patch string) ResourceUpdatedNotificationOption {
+	return func(n *ResourceUpdatedNotification) error {
+		if patch == "" {
+			return fmt.Errorf("patch cannot be empty")
+		}
+		n.Params.Patch = &patch
+		return nil
+	}
 }
 
 type ListResourcesResult struct {
@@ -203,11 +912,98 @@ type ListResourcesResult struct {
 	Resources  []Resource `json:"resources"`
 }
 
+// Validate checks every resource, rejects duplicate URIs and an empty or
+// blank cursor. All problems are reported together as a ValidationError.
+func (r *ListResourcesResult) Validate() error {
+	var failures []ValidationFailure
+
+	if r.NextCursor != nil && strings.TrimSpace(*r.NextCursor) == "" {
+		failures = append(failures, ValidationFailure{
+			Field: "nextCursor",
+			Path:  "/nextCursor",
+			Error: "cursor cannot be empty",
+		})
+	}
+
+	seen := make(map[string]int, len(r.Resources))
+	for i := range r.Resources {
+		res := &r.Resources[i]
+		path := fmt.Sprintf("/resources/%d", i)
+
+		if err := res.Validate(); err != nil {
+			failures = append(failures, ValidationFailure{
+				Field: "resources",
+				Path:  path,
+				Error: err.Error(),
+			})
+		}
+
+		if res.URI == "" {
+			continue
+		}
+		if first, ok := seen[res.URI]; ok {
+			failures = append(failures, ValidationFailure{
+				Field: "uri",
+				Path:  path + "/uri",
+				Error: fmt.Sprintf("duplicate URI %s, first listed at index %d", res.URI, first),
+			})
+			continue
+		}
+		seen[res.URI] = i
+	}
+
+	if len(failures) > 0 {
+		return ValidationError{Validation: failures}
+	}
+	return nil
+}
+
 type ListResourceTemplatesResult struct {
 	NextCursor        *string            `json:"nextCursor,omitempty"`
 	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
 }
 
+const MethodListResourceTemplates = "resources/templates/list"
+
+// ListResourceTemplatesRequestOption configures ListResourceTemplatesRequest
+type ListResourceTemplatesRequestOption func(*ListResourceTemplatesRequest) error
+
+// ListResourceTemplatesRequest represents a request to list resource templates
+type ListResourceTemplatesRequest struct {
+	Method string                      `json:"method"`
+	Params ListResourceTemplatesParams `json:"params"`
+}
+
+type ListResourceTemplatesParams struct {
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+func NewListResourceTemplatesRequest(opts ...ListResourceTemplatesRequestOption) (*ListResourceTemplatesRequest, error) {
+	req := &ListResourceTemplatesRequest{
+		Method: MethodListResourceTemplates,
+	}
+
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, fmt.Errorf("applying list resource templates request option: %w", err)
+		}
+	}
+
+	return req, nil
+}
+
+// ListResourceTemplatesRequest options
+
+func WithTemplatesCursor(cursor string) ListResourceTemplatesRequestOption {
+	return func(r *ListResourceTemplatesRequest) error {
+		if cursor == "" {
+			return fmt.Errorf("cursor cannot be empty")
+		}
+		r.Params.Cursor = &cursor
+		return nil
+	}
+}
+
 /* Usage Example:
 func ExampleResource() {
     // Create a new resource
@@ -216,6 +1012,7 @@ func ExampleResource() {
         "Configuration",
         WithResourceDescription("Application configuration file"),
         WithResourceMimeType("application/yaml"),
+        WithResourceMeta("internalId", "cfg-42"),
         WithResourceAnnotations(&Annotations{
             Audience: []Role{RoleAssistant},
             Priority: ptr(0.8),
@@ -236,6 +1033,20 @@ func ExampleResource() {
         log.Fatal(err)
     }
 
+    // Offer completions for the {env} variable
+    envTemplate, err := NewResourceTemplate(
+        "EnvConfig",
+        "file:///configs/{env}/config.yaml",
+        WithTemplateCompletion("env", StaticCompletions("dev", "staging", "prod")),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+    completions := NewCompletionRegistry()
+    if err := completions.RegisterTemplateCompletions(envTemplate); err != nil {
+        log.Fatal(err)
+    }
+
     // Create resource content
     content, err := NewResourceContent(
         "file:///path/to/config.yaml",
@@ -244,20 +1055,94 @@ func ExampleResource() {
         WithContentAnnotations(&Annotations{
             Audience: []Role{RoleAssistant},
         }),
+        WithContentSource("https://config.example.com/prod"),
     )
     if err != nil {
         log.Fatal(err)
     }
 
+    // Annotations computed only when the resource is listed
+    lazy, err := NewResource(
+        "file:///data/large.csv",
+        "Dataset",
+        WithResourceAnnotationsFunc(func() (*Annotations, error) {
+            return &Annotations{Priority: ptr(score("file:///data/large.csv"))}, nil
+        }),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+    if err := lazy.ResolveAnnotations(); err != nil {
+        log.Fatal(err)
+    }
+
+    // Shrink a large text payload for stdio; readers call DecompressContent
+    compressed, err := CompressContent(content)
+    if err != nil {
+        log.Fatal(err)
+    }
+    restored, err := DecompressContent(compressed, 16<<20)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Attach a checksum so clients can detect corruption and skip re-fetching
+    sum, err := ComputeChecksum(content)
+    if err != nil {
+        log.Fatal(err)
+    }
+    content.Checksum = &sum
+    if err := compressed.VerifyChecksum(); err != nil {
+        log.Print(err) // compressed was made before the checksum was attached
+    }
+
     // Example of resource listing
     listResult := ListResourcesResult{
         Resources: []Resource{*resource},
     }
+    if err := listResult.Validate(); err != nil {
+        log.Fatal(err) // e.g. validation failed: /resources/1/uri: duplicate URI ...
+    }
 
     // Example of reading resource
     readResult := ReadResourceResult{
         Contents: []ResourceContent{*content},
     }
+
+    // Large directory reads are served 50 blocks at a time; the client sends
+    // NextCursor back in ReadResourceRequest.Cursor
+    page, err := PageResourceContents(entries, request.Cursor, 50)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Read lines 100-149 of a large log without transferring the rest
+    window, err := NewReadResourceRequest("file:///var/log/app.log",
+        WithResourceContentRange(ContentRange{Start: 100, End: 150, Unit: RangeUnitLines}),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+    tail, err := store.Read(window) // tail.Contents[0].Range.Total holds the line count
+
+    // Notify subscribers with a patch when only part of a large file changed
+    var opts []ResourceUpdatedNotificationOption
+    if patch, ok := DiffResourceContent(previous, current); ok && patch != "" {
+        opts = append(opts, WithResourcePatch(patch))
+    }
+    updated, err := NewResourceUpdatedNotification(current.URI, opts...)
+    if err != nil {
+        log.Fatal(err)
+    }
+    // Client side: current, err := ApplyResourcePatch(cached, *updated.Params.Patch)
+
+    // Example of requesting the next page of templates
+    templatesRequest, err := NewListResourceTemplatesRequest(
+        WithTemplatesCursor("page-2"),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
 }
 
 // Helper function for float64 pointers
@@ -0,0 +1,438 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResourceReader produces the contents of a static resource
+type ResourceReader func() (*ReadResourceResult, error)
+
+// TemplateReader produces the contents of a templated resource from the
+// variables extracted from the requested URI
+type TemplateReader func(vars map[string]string) (*ReadResourceResult, error)
+
+// ResourceStore serves resources/read requests from registered resources and
+// resource templates. Exact URIs take precedence; otherwise templates are tried
+// in registration order. It is safe for concurrent use.
+type ResourceStore struct {
+	mu        sync.RWMutex
+	resources map[string]storedResource
+	templates []storedTemplate
+}
+
+type storedResource struct {
+	resource Resource
+	read     ResourceReader
+	open     BlobOpener // set instead of read for streamed resources
+	stream   []StreamOption
+}
+
+type storedTemplate struct {
+	template ResourceTemplate
+	read     TemplateReader
+}
+
+// ErrResourceReadOnly is returned when replacing or removing a resource
+// registered as read-only
+var ErrResourceReadOnly = errors.New("resource is read-only")
+
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{resources: make(map[string]storedResource)}
+}
+
+// Register sets the reader for a resource, replacing any previous one unless
+// that is read-only
+func (s *ResourceStore) Register(resource Resource, read ResourceReader) error {
+	if err := resource.Validate(); err != nil {
+		return err
+	}
+	if read == nil {
+		return fmt.Errorf("resource reader cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkWritable(resource.URI); err != nil {
+		return err
+	}
+	s.resources[resource.URI] = storedResource{resource: resource, read: read}
+	return nil
+}
+
+// RegisterStream sets a blob resource whose bytes are streamed as progress
+// notifications by ReadStreaming. Plain Read falls back to an inline base64
+// blob, for clients that sent no progress token. A read-only resource is not
+// replaced.
+func (s *ResourceStore) RegisterStream(resource Resource, open BlobOpener, opts ...StreamOption) error {
+	if err := resource.Validate(); err != nil {
+		return err
+	}
+	if open == nil {
+		return fmt.Errorf("blob opener cannot be nil")
+	}
+	cfg := streamConfig{}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return fmt.Errorf("applying stream option: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkWritable(resource.URI); err != nil {
+		return err
+	}
+	s.resources[resource.URI] = storedResource{resource: resource, open: open, stream: opts}
+	return nil
+}
+
+// Unregister removes a resource, reporting whether it was registered. A
+// read-only resource is kept and ErrResourceReadOnly returned.
+func (s *ResourceStore) Unregister(uri string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkWritable(uri); err != nil {
+		return false, err
+	}
+	_, ok := s.resources[uri]
+	delete(s.resources, uri)
+	return ok, nil
+}
+
+// checkWritable fails if uri is registered as read-only. Callers hold s.mu.
+func (s *ResourceStore) checkWritable(uri string) error {
+	if sr, ok := s.resources[uri]; ok && sr.resource.IsReadOnly() {
+		return fmt.Errorf("%w: %s", ErrResourceReadOnly, uri)
+	}
+	return nil
+}
+
+// RegisterTemplate sets the reader for URIs matching the template, replacing
+// any reader registered for the same URI template
+func (s *ResourceStore) RegisterTemplate(rt ResourceTemplate, read TemplateReader) error {
+	if rt.URITemplate == "" {
+		return fmt.Errorf("URI template cannot be empty")
+	}
+	if read == nil {
+		return fmt.Errorf("template reader cannot be nil")
+	}
+	if _, err := parseURITemplate(rt.URITemplate); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, st := range s.templates {
+		if st.template.URITemplate == rt.URITemplate {
+			s.templates[i] = storedTemplate{template: rt, read: read}
+			return nil
+		}
+	}
+	s.templates = append(s.templates, storedTemplate{template: rt, read: read})
+	return nil
+}
+
+// Read handles a resources/read request. URIs matching neither a resource nor
+// a template yield an invalid params error. A request with a Range gets only
+// that window of each text content; ranges over blobs, or starting past the
+// end of the text, yield an invalid params error.
+func (s *ResourceStore) Read(req *ReadResourceRequest) (*ReadResourceResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("read resource request cannot be nil")
+	}
+	if req.Range == nil {
+		return s.read(req)
+	}
+
+	if err := req.Range.Validate(); err != nil {
+		return nil, &ErrorInfo{Code: ErrInvalidParams, Message: fmt.Sprintf("Invalid range: %v", err)}
+	}
+	result, err := s.read(req)
+	if err != nil {
+		return nil, err
+	}
+	return applyContentRange(result, *req.Range)
+}
+
+func (s *ResourceStore) read(req *ReadResourceRequest) (*ReadResourceResult, error) {
+	s.mu.RLock()
+	sr, ok := s.resources[req.URI]
+	templates := s.templates
+	s.mu.RUnlock()
+
+	if ok {
+		if sr.open != nil {
+			return sr.readInline(context.Background())
+		}
+		return sr.read()
+	}
+
+	for _, st := range templates {
+		if vars, ok := ExtractVariables(st.template.URITemplate, req.URI); ok {
+			return st.read(vars)
+		}
+	}
+
+	return nil, &ErrorInfo{
+		Code:    ErrInvalidParams,
+		Message: fmt.Sprintf("Resource not found: %s", req.URI),
+	}
+}
+
+// ReadStreaming handles a resources/read request like Read, except that
+// streamed resources are sent to send as progress notifications for token
+func (s *ResourceStore) ReadStreaming(ctx context.Context, req *ReadResourceRequest, token ProgressToken, send func(*ProgressNotification) error) (*ReadResourceResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("read resource request cannot be nil")
+	}
+
+	s.mu.RLock()
+	sr, ok := s.resources[req.URI]
+	s.mu.RUnlock()
+
+	if !ok || sr.open == nil || req.Range != nil {
+		return s.Read(req)
+	}
+
+	rc, err := sr.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := StreamResourceContent(ctx, token, req.URI, rc, send, sr.stream...)
+	if err != nil {
+		return nil, err
+	}
+	content.MimeType = sr.resource.MimeType
+	return &ReadResourceResult{Contents: []ResourceContent{*content}}, nil
+}
+
+// applyContentRange cuts every content of result down to r. The checksums of
+// whole contents no longer apply and are dropped.
+func applyContentRange(result *ReadResourceResult, r ContentRange) (*ReadResourceResult, error) {
+	ranged := *result
+	ranged.Contents = make([]ResourceContent, len(result.Contents))
+	for i, content := range result.Contents {
+		if content.Text == nil {
+			return nil, &ErrorInfo{
+				Code:    ErrInvalidParams,
+				Message: fmt.Sprintf("Range reads apply to text content only: %s", content.URI),
+			}
+		}
+		text, covered, err := r.SliceText(*content.Text)
+		if err != nil {
+			return nil, &ErrorInfo{Code: ErrInvalidParams, Message: fmt.Sprintf("Invalid range: %v", err)}
+		}
+		content.Text = &text
+		content.Range = &covered
+		content.Checksum = nil
+		ranged.Contents[i] = content
+	}
+	return &ranged, nil
+}
+
+// readInline reads a streamed resource whole into a base64 blob
+func (sr storedResource) readInline(ctx context.Context) (*ReadResourceResult, error) {
+	rc, err := sr.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("reading resource %s: %w", sr.resource.URI, err)
+	}
+	blob := base64.StdEncoding.EncodeToString(data)
+	return &ReadResourceResult{Contents: []ResourceContent{{
+		URI:      sr.resource.URI,
+		Blob:     &blob,
+		MimeType: sr.resource.MimeType,
+	}}}, nil
+}
+
+// Resources returns the registered resources ordered by URI
+func (s *ResourceStore) Resources() []Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resources := make([]Resource, 0, len(s.resources))
+	for _, sr := range s.resources {
+		resources = append(resources, sr.resource)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+	return resources
+}
+
+// Templates returns the registered resource templates in registration order
+func (s *ResourceStore) Templates() []ResourceTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]ResourceTemplate, len(s.templates))
+	for i, st := range s.templates {
+		templates[i] = st.template
+	}
+	return templates
+}
+
+// ExtractVariables matches uri against an RFC 6570 URI template and returns
+// the decoded value of each variable. Simple ({var}), reserved ({+var}),
+// fragment ({#var}), label ({.var}) and path ({/var}) expressions with a single
+// variable are supported. A variable matches up to the next literal in the
+// template; simple, label and path values stop at a "/", and values without
+// a prefix must not be empty. ok is false if uri
+// does not match or the template uses other expressions.
+func ExtractVariables(uriTemplate, uri string) (vars map[string]string, ok bool) {
+	parts, err := parseURITemplate(uriTemplate)
+	if err != nil {
+		return nil, false
+	}
+
+	vars = make(map[string]string)
+	rest := uri
+	for i, part := range parts {
+		if part.name == "" {
+			if !strings.HasPrefix(rest, part.literal) {
+				return nil, false
+			}
+			rest = rest[len(part.literal):]
+			continue
+		}
+
+		if part.literal != "" {
+			// the operator's prefix is only present when the variable is defined
+			if !strings.HasPrefix(rest, part.literal) {
+				continue
+			}
+			rest = rest[len(part.literal):]
+		}
+
+		end := len(rest)
+		if i+1 < len(parts) && parts[i+1].literal != "" {
+			end = strings.Index(rest, parts[i+1].literal)
+			if end < 0 {
+				if parts[i+1].name == "" {
+					return nil, false
+				}
+				end = len(rest)
+			}
+		}
+		value := rest[:end]
+		if value == "" && part.literal == "" {
+			return nil, false
+		}
+		if !part.reserved && strings.Contains(value, "/") {
+			return nil, false
+		}
+
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, false
+		}
+		vars[part.name] = decoded
+		rest = rest[end:]
+	}
+
+	if rest != "" {
+		return nil, false
+	}
+	return vars, true
+}
+
+// templatePart is a literal run of a URI template or a single-variable
+// expression. For expressions, literal holds the operator's prefix.
+type templatePart struct {
+	literal  string
+	name     string
+	reserved bool
+}
+
+func parseURITemplate(uriTemplate string) ([]templatePart, error) {
+	var parts []templatePart
+	rest := uriTemplate
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			parts = append(parts, templatePart{literal: rest})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated expression in URI template %q", uriTemplate)
+		}
+		expr := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		var part templatePart
+		switch {
+		case strings.HasPrefix(expr, "+"):
+			part.reserved = true
+			expr = expr[1:]
+		case strings.HasPrefix(expr, "#"):
+			part.literal, part.reserved = "#", true
+			expr = expr[1:]
+		case strings.HasPrefix(expr, "."), strings.HasPrefix(expr, "/"):
+			part.literal = expr[:1]
+			expr = expr[1:]
+		}
+		if expr == "" || strings.ContainsAny(expr, "+#./;?&,:*{") {
+			return nil, fmt.Errorf("unsupported expression {%s} in URI template %q", expr, uriTemplate)
+		}
+		if len(parts) > 0 && parts[len(parts)-1].name != "" && parts[len(parts)-1].literal == "" && part.literal == "" {
+			return nil, fmt.Errorf("adjacent variables in URI template %q cannot be matched", uriTemplate)
+		}
+
+		part.name = expr
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+/* Usage Example:
+func ExampleResourceStore() {
+    store := NewResourceStore()
+
+    template, err := NewResourceTemplate("user-profile", "users://{id}/profile",
+        WithTemplateMimeType("application/json"),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    err = store.RegisterTemplate(*template, func(vars map[string]string) (*ReadResourceResult, error) {
+        profile, err := loadProfile(vars["id"])
+        if err != nil {
+            return nil, err
+        }
+        content, err := NewResourceContent("users://"+vars["id"]+"/profile",
+            WithContentText(profile),
+            WithContentMimeType("application/json"),
+        )
+        if err != nil {
+            return nil, err
+        }
+        return &ReadResourceResult{Contents: []ResourceContent{*content}}, nil
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Calls the reader with vars {"id": "42"}
+    result, err := store.Read(&ReadResourceRequest{URI: "users://42/profile"})
+}
+*/
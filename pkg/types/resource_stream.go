@@ -0,0 +1,267 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// Streaming large blobs
+//
+// A resources/read request that carries a progress token in its _meta may be
+// answered with the blob streamed as progress notifications instead of inline
+// base64. Each notification uses the request's token, reports the bytes sent
+// so far as its progress (and the size as total, when known) and carries one
+// ResourceChunk under _meta[MetaKeyResourceChunk]. Chunks are numbered from 1
+// in the order they are sent. The response arrives after the last chunk and
+// holds a ResourceContent with Stream set in place of Blob; its Checksum
+// covers the streamed bytes. Clients feed the notifications to a
+// ResourceAssembler and pass it the content from the response to finish.
+
+// MetaKeyResourceChunk is the progress notification _meta key holding a ResourceChunk
+const MetaKeyResourceChunk = "resourceChunk"
+
+// DefaultStreamChunkSize is the number of raw bytes sent per chunk
+const DefaultStreamChunkSize = 256 << 10 // 256 KiB
+
+// ResourceChunk is one piece of a streamed blob
+type ResourceChunk struct {
+	URI      string `json:"uri"`
+	Sequence int    `json:"sequence"`
+	Data     string `json:"data"` // base64 encoded
+}
+
+// ResourceStream replaces Blob in content whose bytes were streamed
+type ResourceStream struct {
+	Chunks int   `json:"chunks"`
+	Size   int64 `json:"size"`
+}
+
+// BlobOpener opens a streamed resource's bytes. The stream is closed once sent.
+type BlobOpener func(ctx context.Context) (io.ReadCloser, error)
+
+// StreamOption configures a streamed resource
+type StreamOption func(*streamConfig) error
+
+type streamConfig struct {
+	chunkSize int
+	size      *int64
+}
+
+// WithStreamChunkSize sets the number of raw bytes sent per chunk
+func WithStreamChunkSize(n int) StreamOption {
+	return func(c *streamConfig) error {
+		if n <= 0 {
+			return fmt.Errorf("stream chunk size must be positive")
+		}
+		c.chunkSize = n
+		return nil
+	}
+}
+
+// WithStreamSize reports the blob's size as the total of every progress notification
+func WithStreamSize(size int64) StreamOption {
+	return func(c *streamConfig) error {
+		if size < 0 {
+			return fmt.Errorf("stream size cannot be negative")
+		}
+		c.size = &size
+		return nil
+	}
+}
+
+// StreamResourceContent reads r to EOF, passing each chunk to send as a
+// progress notification for token, and returns the content to put in the
+// response. MimeType is left for the caller to set.
+func StreamResourceContent(ctx context.Context, token ProgressToken, uri string, r io.Reader, send func(*ProgressNotification) error, opts ...StreamOption) (*ResourceContent, error) {
+	if uri == "" {
+		return nil, fmt.Errorf("resource URI cannot be empty")
+	}
+	if send == nil {
+		return nil, fmt.Errorf("send function cannot be nil")
+	}
+
+	cfg := streamConfig{chunkSize: DefaultStreamChunkSize}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("applying stream option: %w", err)
+		}
+	}
+
+	h := sha256.New()
+	buf := make([]byte, cfg.chunkSize)
+	stream := &ResourceStream{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			stream.Chunks++
+			stream.Size += int64(n)
+
+			chunk := ResourceChunk{
+				URI:      uri,
+				Sequence: stream.Chunks,
+				Data:     base64.StdEncoding.EncodeToString(buf[:n]),
+			}
+			progressOpts := []ProgressNotificationOption{WithProgressMeta(MetaKeyResourceChunk, chunk)}
+			if cfg.size != nil {
+				progressOpts = append(progressOpts, WithProgressTotal(float64(*cfg.size)))
+			}
+			notification, perr := NewProgressNotification(token, float64(stream.Size), progressOpts...)
+			if perr != nil {
+				return nil, perr
+			}
+			if serr := send(notification); serr != nil {
+				return nil, fmt.Errorf("sending chunk %d: %w", stream.Chunks, serr)
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading resource %s: %w", uri, err)
+		}
+	}
+
+	checksum := ChecksumPrefixSHA256 + hex.EncodeToString(h.Sum(nil))
+	return &ResourceContent{URI: uri, Stream: stream, Checksum: &checksum}, nil
+}
+
+// ResourceChunkFromProgress extracts the chunk carried by a progress
+// notification. ok is false for notifications without one.
+func ResourceChunkFromProgress(n *ProgressNotification) (chunk *ResourceChunk, ok bool, err error) {
+	if n == nil {
+		return nil, false, nil
+	}
+	raw, ok := n.Params.Meta[MetaKeyResourceChunk]
+	if !ok {
+		return nil, false, nil
+	}
+
+	// after decoding from the wire the chunk is a generic map
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, true, fmt.Errorf("encoding resource chunk: %w", err)
+	}
+	chunk = &ResourceChunk{}
+	if err := json.Unmarshal(data, chunk); err != nil {
+		return nil, true, fmt.Errorf("decoding resource chunk: %w", err)
+	}
+	return chunk, true, nil
+}
+
+// ResourceAssembler writes the chunks of one streamed read to w in order and
+// verifies the result against the response. It is not safe for concurrent use;
+// feed it from the goroutine that reads notifications.
+type ResourceAssembler struct {
+	token ProgressToken
+	w     io.Writer
+	hash  hash.Hash
+	next  int
+	size  int64
+	err   error
+}
+
+func NewResourceAssembler(token ProgressToken, w io.Writer) (*ResourceAssembler, error) {
+	if w == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+	return &ResourceAssembler{token: token, w: w, hash: sha256.New(), next: 1}, nil
+}
+
+// Add writes the chunk carried by n. Notifications for other tokens and
+// those without a chunk are ignored. After the first error every later call
+// returns it.
+func (a *ResourceAssembler) Add(n *ProgressNotification) error {
+	if a.err != nil {
+		return a.err
+	}
+	if n == nil || n.Params.ProgressToken != a.token {
+		return nil
+	}
+
+	chunk, ok, err := ResourceChunkFromProgress(n)
+	if !ok {
+		return nil
+	}
+	if err != nil {
+		a.err = err
+		return err
+	}
+	if chunk.Sequence != a.next {
+		a.err = fmt.Errorf("resource chunk %d arrived, expected %d", chunk.Sequence, a.next)
+		return a.err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(chunk.Data)
+	if err != nil {
+		a.err = fmt.Errorf("decoding resource chunk %d: %w", chunk.Sequence, err)
+		return a.err
+	}
+	if _, err := a.w.Write(data); err != nil {
+		a.err = fmt.Errorf("writing resource chunk %d: %w", chunk.Sequence, err)
+		return a.err
+	}
+	a.hash.Write(data)
+	a.size += int64(len(data))
+	a.next++
+	return nil
+}
+
+// Finish checks that every chunk announced by the response's content arrived
+// and that the bytes match its checksum
+func (a *ResourceAssembler) Finish(rc ResourceContent) error {
+	if a.err != nil {
+		return a.err
+	}
+	if rc.Stream == nil {
+		return fmt.Errorf("resource content %s was not streamed", rc.URI)
+	}
+	if received := a.next - 1; received != rc.Stream.Chunks || a.size != rc.Stream.Size {
+		return fmt.Errorf("received %d chunks (%d bytes), expected %d (%d bytes)",
+			received, a.size, rc.Stream.Chunks, rc.Stream.Size)
+	}
+	if rc.Checksum != nil {
+		sum := ChecksumPrefixSHA256 + hex.EncodeToString(a.hash.Sum(nil))
+		if sum != *rc.Checksum {
+			return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, *rc.Checksum, sum)
+		}
+	}
+	return nil
+}
+
+/* Usage Example:
+func ExampleStreamResourceContent() {
+    // Server: register a large file to be streamed when the client asks for progress
+    store := NewResourceStore()
+    video, _ := NewResource("file:///videos/intro.mp4", "intro", WithResourceMimeType("video/mp4"))
+    store.RegisterStream(*video, func(ctx context.Context) (io.ReadCloser, error) {
+        return os.Open("/videos/intro.mp4")
+    }, WithStreamChunkSize(512<<10))
+
+    // Client: reassemble the chunks into a file while the read is in flight
+    f, _ := os.Create("intro.mp4")
+    defer f.Close()
+    assembler, _ := NewResourceAssembler(token, f)
+    onProgress := func(n *ProgressNotification) {
+        if err := assembler.Add(n); err != nil {
+            log.Print(err)
+        }
+    }
+
+    // ... once the resources/read response arrives:
+    if err := assembler.Finish(result.Contents[0]); err != nil {
+        log.Fatal(err)
+    }
+}
+*/
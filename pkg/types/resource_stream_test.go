@@ -0,0 +1,234 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+// overTheWire round-trips v through JSON, as a client receives it
+func overTheWire[T any](t *testing.T, v T) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out T
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// streamBlob streams data in chunks of chunkSize, returning the notifications
+// and content as the client receives them
+func streamBlob(t *testing.T, data []byte, chunkSize int, opts ...StreamOption) ([]*ProgressNotification, ResourceContent) {
+	t.Helper()
+	var sent []*ProgressNotification
+	send := func(n *ProgressNotification) error {
+		sent = append(sent, overTheWire(t, n))
+		return nil
+	}
+	opts = append(opts, WithStreamChunkSize(chunkSize))
+	content, err := StreamResourceContent(context.Background(), 7, "file:///blob.bin", bytes.NewReader(data), send, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sent, overTheWire(t, *content)
+}
+
+func TestStreamResourceContentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		wantChunks int
+	}{
+		{name: "empty", size: 0, wantChunks: 0},
+		{name: "one byte", size: 1, wantChunks: 1},
+		{name: "just under a chunk", size: 15, wantChunks: 1},
+		{name: "exactly one chunk", size: 16, wantChunks: 1},
+		{name: "just over a chunk", size: 17, wantChunks: 2},
+		{name: "several chunks", size: 56, wantChunks: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for i := range data {
+				data[i] = byte(i * 7)
+			}
+			sent, content := streamBlob(t, data, 16, WithStreamSize(int64(tt.size)))
+
+			if len(sent) != tt.wantChunks || content.Stream == nil || content.Stream.Chunks != tt.wantChunks {
+				t.Fatalf("sent %d chunks, content %+v, want %d", len(sent), content.Stream, tt.wantChunks)
+			}
+			if content.Blob != nil || content.Checksum == nil {
+				t.Errorf("content = %+v, want a checksum and no inline blob", content)
+			}
+			var last float64
+			for i, n := range sent {
+				if n.Params.Progress <= last || n.Params.Total == nil || *n.Params.Total != float64(tt.size) {
+					t.Errorf("chunk %d reports %v of %v after %v", i+1, n.Params.Progress, n.Params.Total, last)
+				}
+				last = n.Params.Progress
+			}
+
+			var buf bytes.Buffer
+			assembler, err := NewResourceAssembler(7, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range sent {
+				if err := assembler.Add(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := assembler.Finish(content); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("reassembled %d bytes, want %d", buf.Len(), len(data))
+			}
+		})
+	}
+}
+
+func TestResourceAssemblerRejectsBrokenStreams(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefgh"), 8) // four 16-byte chunks
+	other, err := NewProgressNotification(8, 1, WithProgressMeta(MetaKeyResourceChunk, ResourceChunk{URI: "file:///blob.bin", Sequence: 1, Data: "AAAA"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mangle  func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification
+		wantErr error
+	}{
+		{
+			name: "out of order",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				sent[1], sent[2] = sent[2], sent[1]
+				return sent
+			},
+		},
+		{
+			name: "missing middle chunk",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				return append(sent[:1], sent[2:]...)
+			},
+		},
+		{
+			name: "missing last chunk",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				return sent[:len(sent)-1]
+			},
+		},
+		{
+			name: "repeated chunk",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				return append(sent[:2], sent[1:]...)
+			},
+		},
+		{
+			name: "checksum mismatch",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				sum := ChecksumPrefixSHA256 + "00"
+				content.Checksum = &sum
+				return sent
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name: "corrupted chunk",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				chunk := sent[0].Params.Meta[MetaKeyResourceChunk].(map[string]interface{})
+				chunk["data"] = base64.StdEncoding.EncodeToString([]byte("ABCDEFGHabcdefgh"))
+				return sent
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name: "chunk for another token does not count",
+			mangle: func(sent []*ProgressNotification, content *ResourceContent) []*ProgressNotification {
+				return append([]*ProgressNotification{overTheWire(t, other)}, sent[:len(sent)-1]...)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, content := streamBlob(t, data, 16)
+			sent = tt.mangle(sent, &content)
+
+			assembler, err := NewResourceAssembler(7, io.Discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range sent {
+				if err = assembler.Add(n); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = assembler.Finish(content)
+			}
+			if err == nil {
+				t.Fatal("broken stream was accepted")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			// the first error sticks
+			if again := assembler.Finish(content); again == nil {
+				t.Error("Finish succeeded after an error")
+			}
+		})
+	}
+}
+
+func TestResourceStoreStreamsOnlyWithToken(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff, 0x00}, 40)
+	store := NewResourceStore()
+	resource, err := NewResource("file:///blob.bin", "blob", WithResourceMimeType("application/octet-stream"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.RegisterStream(*resource, func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, WithStreamChunkSize(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a progress token the blob is sent inline
+	result, err := store.Read(&ReadResourceRequest{URI: "file:///blob.bin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inline := result.Contents[0]
+	if inline.Blob == nil || inline.Stream != nil {
+		t.Fatalf("content = %+v, want an inline blob", inline)
+	}
+	if got, err := base64.StdEncoding.DecodeString(*inline.Blob); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("inline blob = %v (%v), want the resource's bytes", got, err)
+	}
+
+	var sent []*ProgressNotification
+	result, err = store.ReadStreaming(context.Background(), &ReadResourceRequest{URI: "file:///blob.bin"}, 3, func(n *ProgressNotification) error {
+		sent = append(sent, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed := result.Contents[0]
+	if streamed.Blob != nil || streamed.Stream == nil || streamed.Stream.Chunks != 3 || len(sent) != 3 {
+		t.Errorf("content = %+v after %d chunks, want 3 streamed chunks", streamed, len(sent))
+	}
+	if streamed.MimeType == nil || *streamed.MimeType != "application/octet-stream" {
+		t.Errorf("mime type = %v, want the resource's", streamed.MimeType)
+	}
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestContentRangeSliceText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		rng     ContentRange
+		want    string
+		covered ContentRange
+	}{
+		{"bytes", "abcdef", ContentRange{Start: 1, End: 3}, "bc", ContentRange{Start: 1, End: 3}},
+		{"bytes end clamped", "abc", ContentRange{Start: 1, End: 10}, "bc", ContentRange{Start: 1, End: 3}},
+		{"bytes last byte", "abc", ContentRange{Start: 2, End: 3}, "c", ContentRange{Start: 2, End: 3}},
+		{"bytes widened to runes", "héllo", ContentRange{Start: 2, End: 3}, "é", ContentRange{Start: 1, End: 3}},
+		{"lines", "a\nb\nc\n", ContentRange{Start: 1, End: 2, Unit: RangeUnitLines}, "b\n", ContentRange{Start: 1, End: 2}},
+		{"lines end clamped", "a\nb\nc", ContentRange{Start: 1, End: 9, Unit: RangeUnitLines}, "b\nc", ContentRange{Start: 1, End: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, covered, err := tt.rng.SliceText(tt.text)
+			if err != nil {
+				t.Fatalf("SliceText: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+			if covered.Start != tt.covered.Start || covered.End != tt.covered.End {
+				t.Errorf("covered = [%d, %d), want [%d, %d)", covered.Start, covered.End, tt.covered.Start, tt.covered.End)
+			}
+		})
+	}
+}
+
+func TestContentRangeSliceTextPastEnd(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		rng  ContentRange
+	}{
+		{"bytes at end", "abc", ContentRange{Start: 3, End: 10}},
+		{"bytes past end", "abc", ContentRange{Start: 5, End: 10}},
+		{"bytes empty text", "", ContentRange{Start: 0, End: 1}},
+		{"lines at end", "a\nb\n", ContentRange{Start: 2, End: 3, Unit: RangeUnitLines}},
+		{"lines past end", "a\nb\n", ContentRange{Start: 7, End: 9, Unit: RangeUnitLines}},
+		{"lines empty text", "", ContentRange{Start: 0, End: 1, Unit: RangeUnitLines}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.rng.SliceText(tt.text); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestResourceStoreReadRangePastEnd(t *testing.T) {
+	store := NewResourceStore()
+	resource, err := NewResource("file:///abc.txt", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Register(*resource, func() (*ReadResourceResult, error) {
+		text := "abc"
+		return &ReadResourceResult{Contents: []ResourceContent{{URI: resource.URI, Text: &text}}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = store.Read(&ReadResourceRequest{URI: resource.URI, Range: &ContentRange{Start: 3, End: 10}})
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Code != ErrInvalidParams {
+		t.Fatalf("err = %v, want an invalid params error", err)
+	}
+}
+
+func TestCompressContentRoundTrip(t *testing.T) {
+	text := "plain text"
+	blob := base64.StdEncoding.EncodeToString([]byte("utf-8 bytes that are not text"))
+	binary := "application/octet-stream"
+	tests := []struct {
+		name string
+		rc   ResourceContent
+	}{
+		{"text", ResourceContent{URI: "file:///a.txt", Text: &text}},
+		{"text with binary mime type", ResourceContent{URI: "file:///a.bin", Text: &text, MimeType: &binary}},
+		{"utf-8 blob without mime type", ResourceContent{URI: "file:///b", Blob: &blob}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := CompressContent(&tt.rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded ResourceContent
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			restored, err := DecompressContent(&decoded, 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (restored.Text != nil) != (tt.rc.Text != nil) {
+				t.Fatalf("restored text = %v, blob = %v; want the original kind", restored.Text, restored.Blob)
+			}
+			if tt.rc.Text != nil && *restored.Text != *tt.rc.Text {
+				t.Errorf("text = %q, want %q", *restored.Text, *tt.rc.Text)
+			}
+			if tt.rc.Blob != nil && *restored.Blob != *tt.rc.Blob {
+				t.Errorf("blob = %q, want %q", *restored.Blob, *tt.rc.Blob)
+			}
+		})
+	}
+}
+
+func TestResourceContentUnmarshalValidatesEncoding(t *testing.T) {
+	for _, raw := range []string{
+		`{"uri":"file:///a","blob":"AA==","encoding":"br"}`,
+		`{"uri":"file:///a","text":"a","encoding":"gzip"}`,
+		`{"uri":"file:///a","blob":"AA==","encodedText":true}`,
+	} {
+		var rc ResourceContent
+		if err := json.Unmarshal([]byte(raw), &rc); err == nil {
+			t.Errorf("Unmarshal(%s): expected an error", raw)
+		}
+	}
+}
+
+func TestResourceStoreReadOnly(t *testing.T) {
+	store := NewResourceStore()
+	read := func() (*ReadResourceResult, error) { return &ReadResourceResult{}, nil }
+
+	locked, err := NewResource("file:///locked.txt", "locked", WithResourceReadOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := NewResource("file:///open.txt", "open", WithResourceReadOnly(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*Resource{locked, open} {
+		if err := store.Register(*r, read); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Register(*locked, read); !errors.Is(err, ErrResourceReadOnly) {
+		t.Errorf("Register over read-only = %v, want ErrResourceReadOnly", err)
+	}
+	opener := func(ctx context.Context) (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("x")), nil }
+	if err := store.RegisterStream(*locked, opener); !errors.Is(err, ErrResourceReadOnly) {
+		t.Errorf("RegisterStream over read-only = %v, want ErrResourceReadOnly", err)
+	}
+	if ok, err := store.Unregister(locked.URI); ok || !errors.Is(err, ErrResourceReadOnly) {
+		t.Errorf("Unregister read-only = %v, %v; want false, ErrResourceReadOnly", ok, err)
+	}
+	if len(store.Resources()) != 2 {
+		t.Errorf("read-only resource was removed")
+	}
+
+	if err := store.Register(*open, read); err != nil {
+		t.Errorf("Register over writable: %v", err)
+	}
+	if ok, err := store.Unregister(open.URI); !ok || err != nil {
+		t.Errorf("Unregister writable = %v, %v; want true, nil", ok, err)
+	}
+	if ok, err := store.Unregister(open.URI); ok || err != nil {
+		t.Errorf("Unregister missing = %v, %v; want false, nil", ok, err)
+	}
+}
@@ -0,0 +1,363 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultWatchDebounce is how long a file must be quiet before its change is reported
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// FileOp describes what happened to a watched file. The values match fsnotify.Op.
+type FileOp uint32
+
+const (
+	FileCreate FileOp = 1 << iota
+	FileWrite
+	FileRemove
+	FileRename
+	FileChmod
+)
+
+// FileEvent is a change to a watched file
+type FileEvent struct {
+	Name string
+	Op   FileOp
+}
+
+// FileWatcher reports changes to files, in the manner of fsnotify.Watcher.
+// Wrap an fsnotify watcher to use it, or feed events by hand in tests.
+type FileWatcher interface {
+	Add(name string) error
+	Remove(name string) error
+	Events() <-chan FileEvent
+	Errors() <-chan error
+	Close() error
+}
+
+// FileResourceWatcherOption configures FileResourceWatcher
+type FileResourceWatcherOption func(*FileResourceWatcher) error
+
+// FileResourceWatcher registers file:// resources with a ResourceStore and
+// reports changes to the files behind them. A file is reported once it has
+// been quiet for the debounce period: with a ResourceUpdatedNotification if it
+// still exists, otherwise by removing the resource from the store and sending a
+// ResourceListChangedNotification. Files replaced by an editor's atomic save
+// count as updated and stay watched. It is safe for concurrent use.
+type FileResourceWatcher struct {
+	store    *ResourceStore
+	watcher  FileWatcher
+	debounce time.Duration
+	onError  func(error)
+
+	mu      sync.Mutex
+	files   map[string]string // path to URI
+	pending map[string]*time.Timer
+	notify  func(Notification) error // set by Run
+}
+
+func NewFileResourceWatcher(store *ResourceStore, watcher FileWatcher, opts ...FileResourceWatcherOption) (*FileResourceWatcher, error) {
+	if store == nil {
+		return nil, fmt.Errorf("resource store cannot be nil")
+	}
+	if watcher == nil {
+		return nil, fmt.Errorf("file watcher cannot be nil")
+	}
+
+	w := &FileResourceWatcher{
+		store:    store,
+		watcher:  watcher,
+		debounce: DefaultWatchDebounce,
+		onError:  func(error) {},
+		files:    make(map[string]string),
+		pending:  make(map[string]*time.Timer),
+	}
+
+	for _, opt := range opts {
+		if err := opt(w); err != nil {
+			return nil, fmt.Errorf("applying resource watcher option: %w", err)
+		}
+	}
+
+	return w, nil
+}
+
+// FileResourceWatcher options
+
+// WithWatchDebounce sets how long a file must be quiet before its change is
+// reported. Zero reports every event at once.
+func WithWatchDebounce(d time.Duration) FileResourceWatcherOption {
+	return func(w *FileResourceWatcher) error {
+		if d < 0 {
+			return fmt.Errorf("debounce cannot be negative")
+		}
+		w.debounce = d
+		return nil
+	}
+}
+
+// WithWatchErrorHandler receives errors from the file watcher and from
+// sending notifications, which otherwise are dropped
+func WithWatchErrorHandler(handler func(error)) FileResourceWatcherOption {
+	return func(w *FileResourceWatcher) error {
+		if handler == nil {
+			return fmt.Errorf("error handler cannot be nil")
+		}
+		w.onError = handler
+		return nil
+	}
+}
+
+// Store returns the store resources are registered with
+func (w *FileResourceWatcher) Store() *ResourceStore {
+	return w.store
+}
+
+// Register adds a file:// resource to the store and starts watching its file.
+// A nil reader reads the file on every request, as text when it is valid
+// UTF-8 and the resource's MIME type is textual, otherwise as a blob.
+func (w *FileResourceWatcher) Register(resource Resource, read ResourceReader) error {
+	path, err := filePath(resource.URI)
+	if err != nil {
+		return err
+	}
+	if read == nil {
+		read = readFileResource(resource, path)
+	}
+
+	if err := w.watcher.Add(path); err != nil {
+		return fmt.Errorf("watching %s: %w", path, err)
+	}
+	if err := w.store.Register(resource, read); err != nil {
+		w.watcher.Remove(path)
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.files[path] = resource.URI
+	return nil
+}
+
+// Unregister stops watching a resource's file and removes it from the store
+func (w *FileResourceWatcher) Unregister(uri string) error {
+	path, err := filePath(uri)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.files[path]; !ok {
+		return fmt.Errorf("resource %s is not watched", uri)
+	}
+	if _, err := w.store.Unregister(uri); err != nil {
+		return err
+	}
+	w.forget(path)
+	return w.watcher.Remove(path)
+}
+
+// Run sends notifications for file changes to notify until ctx is done or the
+// watcher's event channel closes, then closes the watcher
+func (w *FileResourceWatcher) Run(ctx context.Context, notify func(Notification) error) error {
+	if notify == nil {
+		return fmt.Errorf("notify function cannot be nil")
+	}
+
+	w.mu.Lock()
+	w.notify = notify
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		for path, timer := range w.pending {
+			timer.Stop()
+			delete(w.pending, path)
+		}
+		w.notify = nil
+		w.mu.Unlock()
+		w.watcher.Close()
+	}()
+
+	errs := w.watcher.Errors()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.watcher.Events():
+			if !ok {
+				return nil
+			}
+			w.schedule(event)
+		case err, ok := <-errs:
+			if !ok {
+				// a nil channel is never ready, so a closed one is not polled
+				errs = nil
+				continue
+			}
+			w.onError(err)
+		}
+	}
+}
+
+// schedule (re)starts the debounce timer of the event's file
+func (w *FileResourceWatcher) schedule(event FileEvent) {
+	if event.Op == FileChmod {
+		return
+	}
+	path := filepath.Clean(event.Name)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.files[path]; !ok {
+		return
+	}
+	if timer, ok := w.pending[path]; ok {
+		timer.Stop()
+	}
+	w.pending[path] = time.AfterFunc(w.debounce, func() { w.report(path) })
+}
+
+// report tells clients about the settled state of path
+func (w *FileResourceWatcher) report(path string) {
+	w.mu.Lock()
+	uri, ok := w.files[path]
+	notify := w.notify
+	delete(w.pending, path)
+	if !ok || notify == nil {
+		w.mu.Unlock()
+		return
+	}
+
+	_, err := os.Stat(path)
+	removed := errors.Is(err, fs.ErrNotExist)
+	if removed {
+		w.forget(path)
+	}
+	w.mu.Unlock()
+
+	var n Notification
+	if removed {
+		w.watcher.Remove(path)
+		if _, err := w.store.Unregister(uri); err != nil {
+			w.onError(fmt.Errorf("removing deleted file %s: %w", path, err))
+			return
+		}
+		n = NewResourceListChangedNotification()
+	} else {
+		// a file replaced by rename is a new file; watch it again
+		if err := w.watcher.Add(path); err != nil {
+			w.onError(fmt.Errorf("watching %s: %w", path, err))
+		}
+		n, err = NewResourceUpdatedNotification(uri)
+		if err != nil {
+			w.onError(err)
+			return
+		}
+	}
+	if err := notify(n); err != nil {
+		w.onError(fmt.Errorf("notifying change to %s: %w", uri, err))
+	}
+}
+
+// forget drops path from the watched files. Callers hold w.mu.
+func (w *FileResourceWatcher) forget(path string) {
+	delete(w.files, path)
+	if timer, ok := w.pending[path]; ok {
+		timer.Stop()
+		delete(w.pending, path)
+	}
+}
+
+// filePath converts a file:// URI to a local path
+func filePath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid resource URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" || u.Path == "" {
+		return "", fmt.Errorf("resource URI %q is not a file:// URI", uri)
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), nil
+}
+
+func readFileResource(resource Resource, path string) ResourceReader {
+	return func() (*ReadResourceResult, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading resource %s: %w", resource.URI, err)
+		}
+
+		content := ResourceContent{URI: resource.URI, MimeType: resource.MimeType}
+		if utf8.Valid(data) && isTextMimeType(resource.MimeType) {
+			text := string(data)
+			content.Text = &text
+		} else {
+			blob := base64.StdEncoding.EncodeToString(data)
+			content.Blob = &blob
+		}
+		return &ReadResourceResult{Contents: []ResourceContent{content}}, nil
+	}
+}
+
+/* Usage Example:
+// fsWatcher adapts an fsnotify watcher to FileWatcher
+type fsWatcher struct {
+    *fsnotify.Watcher
+    events chan FileEvent
+}
+
+func newFSWatcher() (*fsWatcher, error) {
+    fw, err := fsnotify.NewWatcher()
+    if err != nil {
+        return nil, err
+    }
+    w := &fsWatcher{Watcher: fw, events: make(chan FileEvent)}
+    go func() {
+        defer close(w.events)
+        for e := range fw.Events {
+            w.events <- FileEvent{Name: e.Name, Op: FileOp(e.Op)}
+        }
+    }()
+    return w, nil
+}
+
+func (w *fsWatcher) Events() <-chan FileEvent { return w.events }
+func (w *fsWatcher) Errors() <-chan error     { return w.Watcher.Errors }
+
+func ExampleFileResourceWatcher() {
+    fw, err := newFSWatcher()
+    if err != nil {
+        log.Fatal(err)
+    }
+    watcher, err := NewFileResourceWatcher(NewResourceStore(), fw,
+        WithWatchDebounce(250*time.Millisecond),
+        WithWatchErrorHandler(func(err error) { log.Print(err) }),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    readme, _ := NewResource("file:///srv/docs/README.md", "README", WithResourceMimeType("text/markdown"))
+    if err := watcher.Register(*readme, nil); err != nil {
+        log.Fatal(err)
+    }
+
+    // Serve the store with the watcher's notifications going to subscribed clients
+    srv, err := server.New(*info, server.WithResourceWatcher(watcher))
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+*/
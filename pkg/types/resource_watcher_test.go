@@ -0,0 +1,67 @@
+package types
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeWatcher feeds events by hand
+type fakeWatcher struct {
+	events chan FileEvent
+	errs   chan error
+}
+
+func (f *fakeWatcher) Add(string) error         { return nil }
+func (f *fakeWatcher) Remove(string) error      { return nil }
+func (f *fakeWatcher) Events() <-chan FileEvent { return f.events }
+func (f *fakeWatcher) Errors() <-chan error     { return f.errs }
+func (f *fakeWatcher) Close() error             { return nil }
+
+func TestFileResourceWatcherClosedErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fw := &fakeWatcher{events: make(chan FileEvent), errs: make(chan error)}
+	close(fw.errs)
+	w, err := NewFileResourceWatcher(NewResourceStore(), fw, WithWatchDebounce(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource, err := NewResource("file://"+filepath.ToSlash(path), "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Register(*resource, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	notified := make(chan Notification, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(context.Background(), func(n Notification) error {
+			notified <- n
+			return nil
+		})
+	}()
+
+	// Run must keep serving events after the error channel closes
+	fw.events <- FileEvent{Name: path, Op: FileWrite}
+	select {
+	case n := <-notified:
+		if _, ok := n.(*ResourceUpdatedNotification); !ok {
+			t.Errorf("notification = %T, want *ResourceUpdatedNotification", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification for the change")
+	}
+
+	close(fw.events)
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
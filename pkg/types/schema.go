@@ -0,0 +1,998 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks a decoded JSON value against the schema. All failures are
+// collected and returned together as a ValidationError. The schema is compiled
+// on every call; use Compile to validate against the same schema repeatedly.
+func (s *JSONSchema) Validate(value interface{}) error {
+	cs, err := s.Compile()
+	if err != nil {
+		return err
+	}
+	return cs.Validate(value)
+}
+
+// DefaultMaxSchemaDepth bounds how deeply nested a schema may be
+const DefaultMaxSchemaDepth = 64
+
+// CompileOption configures Compile
+type CompileOption func(*compileConfig)
+
+type compileConfig struct {
+	maxDepth  int
+	formatter MessageFormatter
+	defs      map[string]JSONSchema
+	compiled  map[string]*CompiledSchema // definitions compiled so far, by name
+	refChain  []string                   // definitions reached through $ref alone from the current schema
+}
+
+// WithMaxDepth overrides DefaultMaxSchemaDepth. Schemas from untrusted sources
+// may be deeply nested or self-referential; exceeding the depth fails compilation
+// instead of exhausting the stack.
+func WithMaxDepth(depth int) CompileOption {
+	return func(c *compileConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// WithMessageFormatter renders validation failures with f instead of
+// EnglishMessages, e.g. to localize them
+func WithMessageFormatter(f MessageFormatter) CompileOption {
+	return func(c *compileConfig) {
+		c.formatter = f
+	}
+}
+
+// CompiledSchema is a JSONSchema prepared for repeated validation. Regex patterns
+// are compiled once and required names are deduplicated up front, so high-throughput
+// tool servers pay that cost per schema rather than per call. A CompiledSchema is
+// immutable and safe for concurrent use.
+type CompiledSchema struct {
+	schema     JSONSchema
+	pattern    *regexp.Regexp
+	required   []string
+	properties map[string]*CompiledSchema
+	items      *CompiledSchema
+	ref        *CompiledSchema
+	formatter  MessageFormatter
+}
+
+// Compile prepares the schema for validation. It fails if any pattern in the
+// schema is not a valid regular expression or the schema nests deeper than the
+// maximum depth. Later changes to s are not reflected in the compiled schema.
+//
+// A $ref must point into the root schema's $defs ("#/$defs/name"); it is
+// resolved once per definition, so recursive definitions are supported.
+func (s *JSONSchema) Compile(opts ...CompileOption) (*CompiledSchema, error) {
+	cfg := compileConfig{maxDepth: DefaultMaxSchemaDepth, formatter: EnglishMessages}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxDepth <= 0 {
+		return nil, fmt.Errorf("max depth must be positive")
+	}
+	if cfg.formatter == nil {
+		return nil, fmt.Errorf("message formatter cannot be nil")
+	}
+	cfg.defs = s.Defs
+	cfg.compiled = make(map[string]*CompiledSchema)
+
+	return compileSchema(*s, "", 1, &cfg)
+}
+
+func compileSchema(s JSONSchema, path string, depth int, cfg *compileConfig) (*CompiledSchema, error) {
+	if depth > cfg.maxDepth {
+		return nil, fmt.Errorf("schema at %q exceeds max depth %d", path, cfg.maxDepth)
+	}
+
+	cs := &CompiledSchema{schema: s, formatter: cfg.formatter}
+
+	if s.Ref != nil {
+		ref, err := resolveRef(*s.Ref, path, depth, cfg)
+		if err != nil {
+			return nil, err
+		}
+		cs.ref = ref
+	}
+
+	if s.Pattern != nil {
+		re, err := regexp.Compile(*s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling pattern at %q: %w", path, err)
+		}
+		cs.pattern = re
+	}
+
+	for _, name := range s.Required {
+		if !containsString(cs.required, name) {
+			cs.required = append(cs.required, name)
+		}
+	}
+
+	// a $ref inside a property or item applies to a different value, so it
+	// may lead back to a definition being compiled without looping
+	chain := cfg.refChain
+	cfg.refChain = nil
+	defer func() { cfg.refChain = chain }()
+
+	if len(s.Properties) > 0 {
+		cs.properties = make(map[string]*CompiledSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			compiled, err := compileSchema(prop, joinPointer(path, name), depth+1, cfg)
+			if err != nil {
+				return nil, err
+			}
+			cs.properties[name] = compiled
+		}
+	}
+
+	if s.Items != nil {
+		compiled, err := compileSchema(*s.Items, path+"/items", depth+1, cfg)
+		if err != nil {
+			return nil, err
+		}
+		cs.items = compiled
+	}
+
+	return cs, nil
+}
+
+// unsupportedSchemaKeywords are validation keywords JSONSchema cannot
+// represent. Dropping them would make a loaded schema accept more than it says.
+var unsupportedSchemaKeywords = []string{
+	"allOf", "anyOf", "oneOf", "not", "if", "then", "else",
+	"multipleOf", "exclusiveMinimum", "exclusiveMaximum",
+	"minItems", "maxItems", "uniqueItems", "contains", "prefixItems", "additionalItems",
+	"minProperties", "maxProperties", "patternProperties", "propertyNames",
+	"dependencies", "dependentRequired", "dependentSchemas",
+	"unevaluatedItems", "unevaluatedProperties",
+}
+
+// checkSchemaKeywords rejects a raw schema using validation keywords that
+// JSONSchema cannot represent, or a non-boolean additionalProperties
+func checkSchemaKeywords(raw []byte) error {
+	var schema interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return err
+	}
+	return checkSchemaObject(schema, "")
+}
+
+func checkSchemaObject(schema interface{}, path string) error {
+	obj, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, keyword := range unsupportedSchemaKeywords {
+		if _, ok := obj[keyword]; ok {
+			return fmt.Errorf("unsupported keyword %q at %q", keyword, path)
+		}
+	}
+	if ap, ok := obj["additionalProperties"]; ok {
+		if _, ok := ap.(bool); !ok {
+			return fmt.Errorf("additionalProperties at %q must be a boolean", path)
+		}
+	}
+
+	for _, keyword := range []string{"properties", "$defs"} {
+		members, _ := obj[keyword].(map[string]interface{})
+		for name, member := range members {
+			if err := checkSchemaObject(member, path+"/"+keyword+joinPointer("", name)); err != nil {
+				return err
+			}
+		}
+	}
+	return checkSchemaObject(obj["items"], path+"/items")
+}
+
+// DefsRefPrefix prefixes references to definitions in the root schema's $defs
+const DefsRefPrefix = "#/$defs/"
+
+// resolveRef compiles the definition ref points to, or returns the one already
+// compiled. The result is registered before compiling so that a definition
+// referring to itself from a property or item resolves to the same, still
+// incomplete, schema. A cycle of definitions that refer to each other through
+// $ref alone would never reach a value to check, so it fails compilation.
+func resolveRef(ref, path string, depth int, cfg *compileConfig) (*CompiledSchema, error) {
+	name, err := refDefName(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %q", err, path)
+	}
+	if containsString(cfg.refChain, name) {
+		return nil, fmt.Errorf("$ref cycle through %q at %q", ref, path)
+	}
+	cfg.refChain = append(cfg.refChain, name)
+	defer func() { cfg.refChain = cfg.refChain[:len(cfg.refChain)-1] }()
+
+	if cs, ok := cfg.compiled[name]; ok {
+		return cs, nil
+	}
+
+	def, ok := cfg.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("unresolved $ref %q at %q", ref, path)
+	}
+
+	cs := &CompiledSchema{}
+	cfg.compiled[name] = cs
+	compiled, err := compileSchema(def, "/$defs/"+pointerEscaper.Replace(name), depth+1, cfg)
+	if err != nil {
+		return nil, err
+	}
+	*cs = *compiled
+	return cs, nil
+}
+
+// refDefName returns the definition name a local $ref points to
+func refDefName(ref string) (string, error) {
+	escaped, ok := strings.CutPrefix(ref, DefsRefPrefix)
+	if !ok || escaped == "" || strings.Contains(escaped, "/") {
+		return "", fmt.Errorf("unsupported $ref %q: must be %s<name>", ref, DefsRefPrefix)
+	}
+	return pointerUnescaper.Replace(escaped), nil
+}
+
+// Validate checks a decoded JSON value against the compiled schema
+func (cs *CompiledSchema) Validate(value interface{}) error {
+	var failures []ValidationFailure
+	cs.validate(value, "", "", &failures)
+
+	if len(failures) > 0 {
+		return ValidationError{Validation: failures}
+	}
+
+	return nil
+}
+
+// validate checks value, located at the JSON Pointer path, and appends any failures.
+// field is the name of the closest enclosing object property.
+func (cs *CompiledSchema) validate(value interface{}, field, path string, failures *[]ValidationFailure) {
+	s := &cs.schema
+	fail := func(keyword string, expected, actual interface{}) {
+		v := SchemaViolation{Keyword: keyword, Field: field, Path: path, Expected: expected, Actual: actual}
+		*failures = append(*failures, ValidationFailure{
+			Field: field,
+			Path:  path,
+			Error: cs.formatter.FormatViolation(v),
+		})
+	}
+
+	if cs.ref != nil {
+		cs.ref.validate(value, field, path, failures)
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		fail("type", s.Type, jsonTypeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		fail("enum", s.Enum, value)
+	}
+
+	if s.Const != nil && !valuesEqual(s.Const, value) {
+		fail("const", s.Const, value)
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("minLength", *s.MinLength, length)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("maxLength", *s.MaxLength, length)
+		}
+		if cs.pattern != nil && !cs.pattern.MatchString(v) {
+			fail("pattern", *s.Pattern, v)
+		}
+	case map[string]interface{}:
+		for _, name := range cs.required {
+			if _, ok := v[name]; !ok {
+				violation := SchemaViolation{Keyword: "required", Field: name, Path: joinPointer(path, name), Expected: name}
+				*failures = append(*failures, ValidationFailure{
+					Field: name,
+					Path:  violation.Path,
+					Error: cs.formatter.FormatViolation(violation),
+				})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		closed := s.AdditionalProperties != nil && !*s.AdditionalProperties
+		for _, name := range names {
+			if prop, ok := cs.properties[name]; ok {
+				prop.validate(v[name], name, joinPointer(path, name), failures)
+			} else if closed {
+				violation := SchemaViolation{Keyword: "additionalProperties", Field: name, Path: joinPointer(path, name), Expected: false, Actual: v[name]}
+				*failures = append(*failures, ValidationFailure{
+					Field: name,
+					Path:  violation.Path,
+					Error: cs.formatter.FormatViolation(violation),
+				})
+			}
+		}
+	case []interface{}:
+		if cs.items != nil {
+			for i, item := range v {
+				cs.items.validate(item, field, joinPointer(path, strconv.Itoa(i)), failures)
+			}
+		}
+	default:
+		if n, ok := toFloat64(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				fail("minimum", *s.Minimum, n)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				fail("maximum", *s.Maximum, n)
+			}
+		}
+	}
+}
+
+// SchemaViolation describes one way a value failed its schema, for a
+// MessageFormatter to put into words
+type SchemaViolation struct {
+	// Keyword is the schema keyword that failed: "type", "enum", "const",
+	// "minLength", "maxLength", "pattern", "required", "additionalProperties",
+	// "minimum" or "maximum"
+	Keyword string
+	Field   string
+	Path    string
+	// Expected is the keyword's value in the schema, such as the JSONSchemaType
+	// for "type", the SchemaEnum for "enum" or the missing property's name for
+	// "required"
+	Expected interface{}
+	// Actual is what was found: the JSON type name for "type", the rune count
+	// for the length keywords, the number for minimum and maximum, otherwise
+	// the value itself. It is nil for "required". For "additionalProperties"
+	// Field and Path name the unexpected member.
+	Actual interface{}
+}
+
+// MessageFormatter renders validation failures, so that they can be localized
+// or reworded without touching validation. Implementations must be safe for
+// concurrent use.
+type MessageFormatter interface {
+	FormatViolation(v SchemaViolation) string
+}
+
+// MessageFormatterFunc adapts a function to the MessageFormatter interface
+type MessageFormatterFunc func(v SchemaViolation) string
+
+func (f MessageFormatterFunc) FormatViolation(v SchemaViolation) string {
+	return f(v)
+}
+
+// EnglishMessages is the default MessageFormatter
+var EnglishMessages MessageFormatter = MessageFormatterFunc(formatEnglish)
+
+func formatEnglish(v SchemaViolation) string {
+	switch v.Keyword {
+	case "type":
+		return fmt.Sprintf("expected %s, got %s", v.Expected, v.Actual)
+	case "enum":
+		return "value is not one of the allowed values"
+	case "const":
+		return fmt.Sprintf("must equal %v", v.Expected)
+	case "minLength":
+		return fmt.Sprintf("length must be at least %d", v.Expected)
+	case "maxLength":
+		return fmt.Sprintf("length must be at most %d", v.Expected)
+	case "pattern":
+		return fmt.Sprintf("must match pattern %s", v.Expected)
+	case "required":
+		return "is required"
+	case "additionalProperties":
+		return "is not an allowed property"
+	case "minimum":
+		return fmt.Sprintf("must be at least %v", v.Expected)
+	case "maximum":
+		return fmt.Sprintf("must be at most %v", v.Expected)
+	default:
+		return fmt.Sprintf("fails %s", v.Keyword)
+	}
+}
+
+// pointerEscaper escapes reference tokens as described in RFC 6901
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func joinPointer(path, token string) string {
+	return path + "/" + pointerEscaper.Replace(token)
+}
+
+func matchesType(t JSONSchemaType, value interface{}) bool {
+	switch t {
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeNumber:
+		_, ok := toFloat64(value)
+		return ok
+	case TypeInteger:
+		_, ok := toInt64(value)
+		return ok
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeNull:
+		return value == nil
+	default:
+		return false
+	}
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return string(TypeNull)
+	case map[string]interface{}:
+		return string(TypeObject)
+	case []interface{}:
+		return string(TypeArray)
+	case string:
+		return string(TypeString)
+	case bool:
+		return string(TypeBoolean)
+	}
+	if _, ok := toFloat64(value); ok {
+		return string(TypeNumber)
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat64 converts any Go numeric value or json.Number to float64
+func toFloat64(value interface{}) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+// toInt64 returns the exact integer held by a numeric value or json.Number.
+// Values with a fractional part or outside the int64 range report false.
+func toInt64(value interface{}) (int64, bool) {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		// integral values written with an exponent or fraction, e.g. 1e3 or 3.0
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return floatToInt64(f)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(u), true
+	case reflect.Float32, reflect.Float64:
+		return floatToInt64(rv.Float())
+	default:
+		return 0, false
+	}
+}
+
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func enumContains(enum SchemaEnum, value interface{}) bool {
+	for _, allowed := range enum {
+		if valuesEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares JSON values, treating numbers of different Go types as equal
+// when they hold the same value. Integers are compared exactly so large values
+// don't collide after float64 rounding.
+func valuesEqual(a, b interface{}) bool {
+	ai, aok := toInt64(a)
+	bi, bok := toInt64(b)
+	if aok && bok {
+		return ai == bi
+	}
+
+	an, aok := toFloat64(a)
+	bn, bok := toFloat64(b)
+	if aok && bok {
+		return an == bn
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// DecodeOption configures DecodeArguments
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	coerce bool
+}
+
+// WithCoercion converts string arguments to the number, integer or boolean the
+// schema expects before validating. Many LLM clients stringify every argument.
+func WithCoercion() DecodeOption {
+	return func(c *decodeConfig) {
+		c.coerce = true
+	}
+}
+
+// DecodeArguments decodes raw tool arguments and validates them against the schema.
+// Numbers are decoded as json.Number so integers keep their exact value.
+func DecodeArguments(raw json.RawMessage, schema *JSONSchema, opts ...DecodeOption) (map[string]interface{}, error) {
+	cfg := decodeConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	args := map[string]interface{}{}
+	if len(raw) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			return nil, fmt.Errorf("decoding arguments: %w", err)
+		}
+		if err := dec.Decode(&struct{}{}); err != io.EOF {
+			return nil, fmt.Errorf("decoding arguments: unexpected data after the arguments object")
+		}
+	}
+
+	if schema == nil {
+		return args, nil
+	}
+
+	var value interface{} = args
+	if cfg.coerce {
+		value = schema.Coerce(value)
+	}
+
+	if err := schema.Validate(value); err != nil {
+		return nil, err
+	}
+
+	return value.(map[string]interface{}), nil
+}
+
+// Coerce converts string values to the types the schema expects, recursing into
+// objects and arrays and following $ref into the schema's $defs. Values that
+// cannot be converted are returned unchanged so validation can report them.
+func (s *JSONSchema) Coerce(value interface{}) interface{} {
+	return s.coerce(value, s.Defs)
+}
+
+func (s *JSONSchema) coerce(value interface{}, defs map[string]JSONSchema) interface{} {
+	resolved := s.resolveRefs(defs)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(resolved.Properties) == 0 {
+			return v
+		}
+		out := make(map[string]interface{}, len(v))
+		for name, propValue := range v {
+			if prop, ok := resolved.Properties[name]; ok {
+				out[name] = prop.coerce(propValue, defs)
+			} else {
+				out[name] = propValue
+			}
+		}
+		return out
+	case []interface{}:
+		if resolved.Items == nil {
+			return v
+		}
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = resolved.Items.coerce(item, defs)
+		}
+		return out
+	case string:
+		return resolved.coerceString(v)
+	default:
+		return value
+	}
+}
+
+// resolveRefs returns s with the definition its $ref points to merged in, so
+// that the definition's keywords apply alongside s's own, following further
+// references the same way. References that do not resolve, or that loop, are
+// left out.
+func (s JSONSchema) resolveRefs(defs map[string]JSONSchema) JSONSchema {
+	seen := make(map[string]bool)
+	for s.Ref != nil {
+		own := s
+		own.Ref = nil
+
+		name, err := refDefName(*s.Ref)
+		def, ok := defs[name]
+		if err != nil || !ok || seen[name] {
+			return own
+		}
+		seen[name] = true
+		s = def.Merge(own)
+	}
+	return s
+}
+
+func (s *JSONSchema) coerceString(v string) interface{} {
+	target := s.Type
+	if target == "" && len(s.Enum) > 0 {
+		// infer the target type from the enum values
+		switch s.Enum[0].(type) {
+		case bool:
+			target = TypeBoolean
+		case string:
+			target = TypeString
+		default:
+			if _, ok := toFloat64(s.Enum[0]); ok {
+				target = TypeNumber
+			}
+		}
+	}
+
+	switch target {
+	case TypeInteger:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(n, 10))
+		}
+	case TypeNumber:
+		// re-emitted so that forms ParseFloat accepts but JSON does not, such
+		// as hex and underscores, never reach the handler as json.Number
+		if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
+
+// Merge returns a new schema combining s with overlay, for composing tool schemas
+// from reusable fragments. Properties are unioned, with properties present in both
+// merged recursively; Required names are concatenated and deduplicated, base first.
+// $defs are unioned, with overlay definitions replacing base ones of the same name.
+// Every other field set on overlay (Type, Items, Enum, Const, the scalar
+// constraints and the annotations) replaces the base value. Neither input is modified and the result
+// shares no maps or slices with them.
+func (s JSONSchema) Merge(overlay JSONSchema) JSONSchema {
+	merged := s.clone()
+
+	if overlay.Type != "" {
+		merged.Type = overlay.Type
+	}
+
+	if len(overlay.Properties) > 0 {
+		if merged.Properties == nil {
+			merged.Properties = make(map[string]JSONSchema, len(overlay.Properties))
+		}
+		for name, prop := range overlay.Properties {
+			if base, ok := merged.Properties[name]; ok {
+				merged.Properties[name] = base.Merge(prop)
+			} else {
+				merged.Properties[name] = prop.clone()
+			}
+		}
+	}
+
+	for _, name := range overlay.Required {
+		if !containsString(merged.Required, name) {
+			merged.Required = append(merged.Required, name)
+		}
+	}
+
+	if overlay.Items != nil {
+		var items JSONSchema
+		if merged.Items != nil {
+			items = merged.Items.Merge(*overlay.Items)
+		} else {
+			items = overlay.Items.clone()
+		}
+		merged.Items = &items
+	}
+
+	if overlay.Enum != nil {
+		merged.Enum = append(SchemaEnum(nil), overlay.Enum...)
+	}
+	if overlay.MinLength != nil {
+		v := *overlay.MinLength
+		merged.MinLength = &v
+	}
+	if overlay.MaxLength != nil {
+		v := *overlay.MaxLength
+		merged.MaxLength = &v
+	}
+	if overlay.Minimum != nil {
+		v := *overlay.Minimum
+		merged.Minimum = &v
+	}
+	if overlay.Maximum != nil {
+		v := *overlay.Maximum
+		merged.Maximum = &v
+	}
+	if overlay.Pattern != nil {
+		v := *overlay.Pattern
+		merged.Pattern = &v
+	}
+	if overlay.Const != nil {
+		merged.Const = overlay.Const
+	}
+	if overlay.Sensitive != nil {
+		v := *overlay.Sensitive
+		merged.Sensitive = &v
+	}
+	if overlay.Ref != nil {
+		v := *overlay.Ref
+		merged.Ref = &v
+	}
+	if overlay.AdditionalProperties != nil {
+		v := *overlay.AdditionalProperties
+		merged.AdditionalProperties = &v
+	}
+	if overlay.Schema != nil {
+		v := *overlay.Schema
+		merged.Schema = &v
+	}
+	if overlay.Title != nil {
+		v := *overlay.Title
+		merged.Title = &v
+	}
+	if overlay.Description != nil {
+		v := *overlay.Description
+		merged.Description = &v
+	}
+	if overlay.Default != nil {
+		merged.Default = overlay.Default
+	}
+	if len(overlay.Defs) > 0 {
+		if merged.Defs == nil {
+			merged.Defs = make(map[string]JSONSchema, len(overlay.Defs))
+		}
+		for name, def := range overlay.Defs {
+			merged.Defs[name] = def.clone()
+		}
+	}
+
+	return merged
+}
+
+// clone returns a deep copy of the schema's maps, slices and pointers
+func (s JSONSchema) clone() JSONSchema {
+	c := s
+
+	if s.Properties != nil {
+		c.Properties = make(map[string]JSONSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			c.Properties[name] = prop.clone()
+		}
+	}
+	if s.Required != nil {
+		c.Required = append([]string(nil), s.Required...)
+	}
+	if s.Items != nil {
+		items := s.Items.clone()
+		c.Items = &items
+	}
+	if s.Enum != nil {
+		c.Enum = append(SchemaEnum(nil), s.Enum...)
+	}
+	if s.MinLength != nil {
+		v := *s.MinLength
+		c.MinLength = &v
+	}
+	if s.MaxLength != nil {
+		v := *s.MaxLength
+		c.MaxLength = &v
+	}
+	if s.Minimum != nil {
+		v := *s.Minimum
+		c.Minimum = &v
+	}
+	if s.Maximum != nil {
+		v := *s.Maximum
+		c.Maximum = &v
+	}
+	if s.Pattern != nil {
+		v := *s.Pattern
+		c.Pattern = &v
+	}
+	if s.Sensitive != nil {
+		v := *s.Sensitive
+		c.Sensitive = &v
+	}
+	if s.Ref != nil {
+		v := *s.Ref
+		c.Ref = &v
+	}
+	if s.AdditionalProperties != nil {
+		v := *s.AdditionalProperties
+		c.AdditionalProperties = &v
+	}
+	if s.Schema != nil {
+		v := *s.Schema
+		c.Schema = &v
+	}
+	if s.Title != nil {
+		v := *s.Title
+		c.Title = &v
+	}
+	if s.Description != nil {
+		v := *s.Description
+		c.Description = &v
+	}
+	if s.Defs != nil {
+		c.Defs = make(map[string]JSONSchema, len(s.Defs))
+		for name, def := range s.Defs {
+			c.Defs[name] = def.clone()
+		}
+	}
+
+	return c
+}
+
+// JSON Schema dialects accepted by Downgrade. The dialect's meta-schema URI,
+// e.g. "http://json-schema.org/draft-07/schema#", is accepted as well.
+const (
+	SchemaDraft04      = "draft-04"
+	SchemaDraft06      = "draft-06"
+	SchemaDraft07      = "draft-07"
+	SchemaDraft2019_09 = "2019-09"
+	SchemaDraft2020_12 = "2020-12"
+)
+
+// ExperimentalSchemaDialect is the experimental client capability in which a
+// client declares the newest JSON Schema dialect it understands, as a string
+const ExperimentalSchemaDialect = "schemaDialect"
+
+// schemaDialectOrder ranks the dialects from oldest to newest
+var schemaDialectOrder = map[string]int{
+	SchemaDraft04:      4,
+	SchemaDraft06:      6,
+	SchemaDraft07:      7,
+	SchemaDraft2019_09: 2019,
+	SchemaDraft2020_12: 2020,
+}
+
+// schemaDialectRank returns the rank of a dialect name or meta-schema URI
+func schemaDialectRank(dialect string) (int, bool) {
+	if rank, ok := schemaDialectOrder[dialect]; ok {
+		return rank, true
+	}
+	for name, rank := range schemaDialectOrder {
+		if strings.Contains(dialect, "/"+name+"/") || strings.Contains(dialect, "/draft/"+name+"/") {
+			return rank, true
+		}
+	}
+	return 0, false
+}
+
+// SchemaDialectFromCapabilities returns the dialect a client declared under
+// ExperimentalSchemaDialect. ok is false if it declared none.
+func SchemaDialectFromCapabilities(caps ClientCapabilities) (dialect string, ok bool) {
+	raw, ok := caps.Experimental[ExperimentalSchemaDialect]
+	if !ok {
+		return "", false
+	}
+	if err := json.Unmarshal(raw, &dialect); err != nil || dialect == "" {
+		return "", false
+	}
+	return dialect, true
+}
+
+// Downgrade returns a copy of the schema that a validator for the target
+// dialect understands. For dialects before draft-06, const becomes a
+// single-value enum. For dialects before 2019-09, which lack $defs, every $ref
+// is replaced by the definition it points to, merged with the keywords beside
+// it, and $defs is dropped. A recursive reference cannot be inlined, so the
+// innermost repetition accepts any value. References that do not resolve are
+// kept. Unknown or current dialects get an unchanged copy.
+func (s JSONSchema) Downgrade(target string) JSONSchema {
+	rank, ok := schemaDialectRank(target)
+	if !ok {
+		return s.clone()
+	}
+
+	d := schemaDowngrade{
+		constToEnum: rank < schemaDialectOrder[SchemaDraft06],
+		inlineRefs:  rank < schemaDialectOrder[SchemaDraft2019_09],
+		defs:        s.Defs,
+	}
+	return d.apply(s, nil)
+}
+
+type schemaDowngrade struct {
+	constToEnum bool
+	inlineRefs  bool
+	defs        map[string]JSONSchema
+}
+
+// apply downgrades s; inlining lists the definitions being inlined around it
+func (d schemaDowngrade) apply(s JSONSchema, inlining []string) JSONSchema {
+	out := s.clone()
+
+	if d.inlineRefs {
+		out.Defs = nil
+		if out.Ref != nil {
+			name, err := refDefName(*out.Ref)
+			def, ok := d.defs[name]
+			if err == nil && ok {
+				out.Ref = nil
+				siblings := d.apply(out, inlining)
+				if containsString(inlining, name) {
+					return siblings
+				}
+				return d.apply(def, append(inlining, name)).Merge(siblings)
+			}
+		}
+	} else if out.Defs != nil {
+		for name, def := range out.Defs {
+			out.Defs[name] = d.apply(def, inlining)
+		}
+	}
+
+	if d.constToEnum && out.Const != nil {
+		out.Enum = SchemaEnum{out.Const}
+		out.Const = nil
+	}
+
+	for name, prop := range out.Properties {
+		out.Properties[name] = d.apply(prop, inlining)
+	}
+	if out.Items != nil {
+		items := d.apply(*out.Items, inlining)
+		out.Items = &items
+	}
+
+	return out
+}
@@ -0,0 +1,200 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompileRejectsRefCycles(t *testing.T) {
+	tests := map[string]string{
+		"self":     `{"$ref":"#/$defs/a","$defs":{"a":{"$ref":"#/$defs/a"}}}`,
+		"pair":     `{"$ref":"#/$defs/a","$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}}}`,
+		"property": `{"properties":{"x":{"$ref":"#/$defs/a"}},"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}}}`,
+	}
+
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			var schema JSONSchema
+			if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := schema.Compile(); err == nil {
+				t.Fatal("expected a $ref cycle error")
+			}
+		})
+	}
+}
+
+func TestCompileAllowsRecursiveDefinitions(t *testing.T) {
+	raw := `{"$ref":"#/$defs/node","$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}}}}`
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := schema.Compile()
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	value := map[string]interface{}{"next": map[string]interface{}{"next": "oops"}}
+	if err := cs.Validate(value); err == nil {
+		t.Fatal("expected a validation error for the innermost node")
+	}
+}
+
+func TestCoerceFollowsRefs(t *testing.T) {
+	ref := DefsRefPrefix + "page"
+	schema := JSONSchema{
+		Type:       TypeObject,
+		Properties: map[string]JSONSchema{"page": {Ref: &ref}},
+		Defs: map[string]JSONSchema{
+			"page": ObjectSchema(map[string]JSONSchema{"size": IntegerSchema}),
+		},
+	}
+
+	coerced := schema.Coerce(map[string]interface{}{
+		"page": map[string]interface{}{"size": "20"},
+	}).(map[string]interface{})
+
+	size := coerced["page"].(map[string]interface{})["size"]
+	if _, ok := size.(string); ok {
+		t.Fatalf("size = %#v, want a number", size)
+	}
+}
+
+func TestCoerceNumberStrings(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"1.5", json.Number("1.5")},
+		{"1e3", json.Number("1000")},
+		{"0x1p4", json.Number("16")},
+		{"NaN", "NaN"},
+		{"Inf", "Inf"},
+		{"-infinity", "-infinity"},
+		{"1e999", "1e999"},
+		{"abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := NumberSchema.Coerce(tt.in)
+			if got != tt.want {
+				t.Errorf("Coerce(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArgumentsRejectsTrailingData(t *testing.T) {
+	for _, raw := range []string{`{"a":1}{"b":2}`, `{"a":1} x`, `{"a":1}]`} {
+		if _, err := DecodeArguments(json.RawMessage(raw), nil); err == nil {
+			t.Errorf("DecodeArguments(%s): expected an error", raw)
+		}
+	}
+	if _, err := DecodeArguments(json.RawMessage(`{"a":1}`+"\n"), nil); err != nil {
+		t.Errorf("trailing whitespace: %v", err)
+	}
+}
+
+func TestJSONSchemaDowngrade(t *testing.T) {
+	page := `"$defs":{"page":{"type":"object","description":"a page","properties":{"size":{"type":"integer"}}}}`
+
+	tests := []struct {
+		name   string
+		schema string
+		target string
+		want   string
+	}{
+		{
+			name:   "const to enum",
+			schema: `{"type":"string","const":"x"}`,
+			target: SchemaDraft04,
+			want:   `{"type":"string","enum":["x"]}`,
+		},
+		{
+			name:   "nested const to enum",
+			schema: `{"type":"object","properties":{"kind":{"const":"a"},"tags":{"type":"array","items":{"const":1}}}}`,
+			target: SchemaDraft04,
+			want:   `{"type":"object","properties":{"kind":{"enum":["a"]},"tags":{"type":"array","items":{"enum":[1]}}}}`,
+		},
+		{
+			name:   "const kept from draft-06",
+			schema: `{"const":"x"}`,
+			target: SchemaDraft06,
+			want:   `{"const":"x"}`,
+		},
+		{
+			name:   "meta-schema URI",
+			schema: `{"const":"x"}`,
+			target: "http://json-schema.org/draft-04/schema#",
+			want:   `{"enum":["x"]}`,
+		},
+		{
+			name:   "ref inlined and defs dropped",
+			schema: `{"type":"object","properties":{"p":{"$ref":"#/$defs/page","description":"the page"}},` + page + `}`,
+			target: SchemaDraft07,
+			want:   `{"type":"object","properties":{"p":{"type":"object","description":"the page","properties":{"size":{"type":"integer"}}}}}`,
+		},
+		{
+			name:   "refs between definitions",
+			schema: `{"$ref":"#/$defs/a","$defs":{"a":{"type":"object","properties":{"b":{"$ref":"#/$defs/b"}}},"b":{"const":1}}}`,
+			target: SchemaDraft04,
+			want:   `{"type":"object","properties":{"b":{"enum":[1]}}}`,
+		},
+		{
+			name:   "recursive definition terminates",
+			schema: `{"$ref":"#/$defs/node","$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}}}}`,
+			target: SchemaDraft07,
+			want:   `{"type":"object","properties":{"next":{}}}`,
+		},
+		{
+			name:   "unresolved ref kept",
+			schema: `{"properties":{"p":{"$ref":"#/$defs/missing"}}}`,
+			target: SchemaDraft07,
+			want:   `{"properties":{"p":{"$ref":"#/$defs/missing"}}}`,
+		},
+		{
+			name:   "refs kept from 2019-09",
+			schema: `{"properties":{"p":{"$ref":"#/$defs/page"}},` + page + `}`,
+			target: SchemaDraft2019_09,
+			want:   `{"properties":{"p":{"$ref":"#/$defs/page"}},` + page + `}`,
+		},
+		{
+			name:   "unknown dialect",
+			schema: `{"const":"x","properties":{"p":{"$ref":"#/$defs/page"}},` + page + `}`,
+			target: "draft-99",
+			want:   `{"const":"x","properties":{"p":{"$ref":"#/$defs/page"}},` + page + `}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema JSONSchema
+			if err := json.Unmarshal([]byte(tt.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			before, _ := json.Marshal(schema)
+
+			got, err := json.Marshal(schema.Downgrade(tt.target))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("Downgrade(%s) = %s, want %s", tt.target, got, tt.want)
+			}
+
+			if after, _ := json.Marshal(schema); string(after) != string(before) {
+				t.Errorf("Downgrade changed the original schema to %s", after)
+			}
+		})
+	}
+}
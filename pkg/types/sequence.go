@@ -0,0 +1,175 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// MetaKeySequence is the _meta key carrying a notification's sequence number
+const MetaKeySequence = "sequence"
+
+// SequenceStamper numbers outgoing notifications so receivers can detect gaps.
+// Numbers start at 1. It is safe for concurrent use.
+type SequenceStamper struct {
+	next atomic.Int64
+}
+
+func NewSequenceStamper() *SequenceStamper {
+	return &SequenceStamper{}
+}
+
+// Stamp adds the next sequence number to the notification's params._meta,
+// keeping any other params and _meta entries
+func (s *SequenceStamper) Stamp(n *JSONRPCNotification) error {
+	params := map[string]json.RawMessage{}
+	if len(n.Params) > 0 {
+		if err := json.Unmarshal(n.Params, &params); err != nil {
+			return fmt.Errorf("notification params must be an object: %w", err)
+		}
+		if params == nil {
+			params = map[string]json.RawMessage{}
+		}
+	}
+
+	meta := map[string]json.RawMessage{}
+	if raw, ok := params["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return fmt.Errorf("notification _meta must be an object: %w", err)
+		}
+		if meta == nil {
+			meta = map[string]json.RawMessage{}
+		}
+	}
+
+	seq, err := json.Marshal(s.next.Add(1))
+	if err != nil {
+		return err
+	}
+	meta[MetaKeySequence] = seq
+
+	rawMeta, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshaling _meta: %w", err)
+	}
+	params["_meta"] = rawMeta
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshaling params: %w", err)
+	}
+	n.Params = rawParams
+	return nil
+}
+
+// SequenceGap describes an unexpected sequence number. Received above Expected
+// means notifications were dropped; below means one arrived late or twice.
+type SequenceGap struct {
+	Method   string
+	Expected int64
+	Received int64
+}
+
+// Missed returns how many notifications were skipped, or 0 for a late arrival
+func (g SequenceGap) Missed() int64 {
+	if g.Received > g.Expected {
+		return g.Received - g.Expected
+	}
+	return 0
+}
+
+func (g SequenceGap) String() string {
+	if g.Received > g.Expected {
+		return fmt.Sprintf("%s: missed %d notification(s), expected sequence %d, got %d",
+			g.Method, g.Missed(), g.Expected, g.Received)
+	}
+	return fmt.Sprintf("%s: out-of-order notification, expected sequence %d, got %d",
+		g.Method, g.Expected, g.Received)
+}
+
+// NotificationSequencer checks the sequence numbers of incoming notifications.
+// Notifications without a sequence number are ignored, so it is harmless
+// against peers that don't stamp. It is safe for concurrent use.
+type NotificationSequencer struct {
+	mu     sync.Mutex
+	last   int64
+	missed int64
+}
+
+func NewNotificationSequencer() *NotificationSequencer {
+	return &NotificationSequencer{}
+}
+
+// Observe records the notification's sequence number and returns a gap when it
+// isn't the one expected. Late arrivals don't move the expected number back.
+func (s *NotificationSequencer) Observe(n *JSONRPCNotification) (*SequenceGap, error) {
+	if len(n.Params) == 0 {
+		return nil, nil
+	}
+
+	var params struct {
+		Meta map[string]interface{} `json:"_meta"`
+	}
+	if err := json.Unmarshal(n.Params, &params); err != nil {
+		return nil, fmt.Errorf("decoding notification params: %w", err)
+	}
+
+	raw, ok := params.Meta[MetaKeySequence]
+	if !ok {
+		return nil, nil
+	}
+	seq, ok := toInt64(raw)
+	if !ok || seq < 1 {
+		return nil, fmt.Errorf("invalid sequence number: %v", raw)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expected := s.last + 1
+	if seq == expected {
+		s.last = seq
+		return nil, nil
+	}
+
+	gap := &SequenceGap{Method: n.Method, Expected: expected, Received: seq}
+	if seq > expected {
+		s.missed += seq - expected
+		s.last = seq
+	}
+	return gap, nil
+}
+
+// Missed returns the total number of notifications skipped so far
+func (s *NotificationSequencer) Missed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.missed
+}
+
+/* Usage Example:
+func ExampleNotificationSequencer() {
+    // Server: stamp every notification before writing it
+    stamper := NewSequenceStamper()
+    notification := &JSONRPCNotification{
+        JSONRPC: JSONRPCVersion,
+        Method:  MethodProgress,
+        Params:  json.RawMessage(`{"progressToken":1,"progress":50}`),
+    }
+    if err := stamper.Stamp(notification); err != nil {
+        log.Fatal(err)
+    }
+    // params: {"_meta":{"sequence":1},"progress":50,"progressToken":1}
+
+    // Client: check every notification as it arrives
+    sequencer := NewNotificationSequencer()
+    gap, err := sequencer.Observe(notification)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if gap != nil {
+        log.Printf("lossy transport: %s", gap)
+    }
+}
+*/
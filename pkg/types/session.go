@@ -0,0 +1,339 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MethodInitialize  = "initialize"
+	MethodInitialized = "notifications/initialized"
+	MethodPing        = "ping"
+)
+
+// protocolVersionLayout is the date format of protocol version strings
+const protocolVersionLayout = "2006-01-02"
+
+// ParseProtocolVersion parses a date-versioned protocol string such as "2024-11-05"
+func ParseProtocolVersion(s string) (time.Time, error) {
+	t, err := time.Parse(protocolVersionLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid protocol version %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// IsNewerProtocol reports whether protocol version a is chronologically newer
+// than b. A version that doesn't parse is never newer, and any valid version is
+// newer than one that doesn't parse.
+func IsNewerProtocol(a, b string) bool {
+	ta, err := ParseProtocolVersion(a)
+	if err != nil {
+		return false
+	}
+	tb, err := ParseProtocolVersion(b)
+	if err != nil {
+		return true
+	}
+	return ta.After(tb)
+}
+
+// NewUnsupportedProtocolVersionError rejects an initialize request whose
+// protocol version the server will not speak
+func NewUnsupportedProtocolVersionError(requested string) *ErrorInfo {
+	return &ErrorInfo{
+		Code:    ErrInvalidParams,
+		Message: fmt.Sprintf("Unsupported protocol version: %s", requested),
+	}
+}
+
+// IsProtocolVersionError reports whether err is a server's rejection of the
+// requested protocol version: an invalid params error whose message mentions
+// the protocol version, as produced by NewUnsupportedProtocolVersionError
+func IsProtocolVersionError(err error) bool {
+	var info *ErrorInfo
+	if !errors.As(err, &info) || info.Code != ErrInvalidParams {
+		return false
+	}
+	return strings.Contains(strings.ToLower(info.Message), "protocol version")
+}
+
+// OlderProtocolVersion returns the newest supported version older than
+// version, or false if there is none
+func OlderProtocolVersion(version string) (string, bool) {
+	for _, v := range SupportedProtocolVersions {
+		if IsNewerProtocol(version, v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// SessionState tracks where a session is in the initialization lifecycle
+type SessionState string
+
+const (
+	SessionUninitialized SessionState = "uninitialized"
+	SessionInitializing  SessionState = "initializing"
+	SessionReady         SessionState = "ready"
+)
+
+// ClientSession enforces the client side of the initialization handshake:
+// initialize first, then notifications/initialized, then everything else.
+// It is safe for concurrent use.
+type ClientSession struct {
+	mu     sync.Mutex
+	state  SessionState
+	result *InitializeResult
+	notify func(*InitializedNotification) error
+}
+
+// NewClientSession creates a session that sends the initialized notification
+// through notify once initialization succeeds
+func NewClientSession(notify func(*InitializedNotification) error) (*ClientSession, error) {
+	if notify == nil {
+		return nil, fmt.Errorf("notify function cannot be nil")
+	}
+
+	return &ClientSession{
+		state:  SessionUninitialized,
+		notify: notify,
+	}, nil
+}
+
+// State returns the current lifecycle state
+func (s *ClientSession) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// InitializeResult returns the server's initialize result once the session is ready
+func (s *ClientSession) InitializeResult() *InitializeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.result
+}
+
+// BeginInitialize must be called before sending the initialize request
+func (s *ClientSession) BeginInitialize() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != SessionUninitialized {
+		return fmt.Errorf("cannot initialize: session is %s", s.state)
+	}
+
+	s.state = SessionInitializing
+	return nil
+}
+
+// CompleteInitialize records the server's result, sends notifications/initialized
+// and marks the session ready
+func (s *ClientSession) CompleteInitialize(result *InitializeResult) error {
+	if result == nil {
+		return fmt.Errorf("initialize result cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != SessionInitializing {
+		return fmt.Errorf("cannot complete initialization: session is %s", s.state)
+	}
+
+	if err := s.notify(NewInitializedNotification(nil)); err != nil {
+		return fmt.Errorf("sending initialized notification: %w", err)
+	}
+
+	s.result = result
+	s.state = SessionReady
+	return nil
+}
+
+// AbortInitialize returns the session to uninitialized after a failed initialize
+func (s *ClientSession) AbortInitialize() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == SessionInitializing {
+		s.state = SessionUninitialized
+	}
+}
+
+// CheckRequest reports whether a request with the given method may be sent now.
+// Pings are allowed at any time.
+func (s *ClientSession) CheckRequest(method string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case method == MethodPing:
+		return nil
+	case method == MethodInitialize:
+		return fmt.Errorf("initialize must go through BeginInitialize")
+	case s.state != SessionReady:
+		return fmt.Errorf("cannot send %s: session is %s", method, s.state)
+	}
+
+	return nil
+}
+
+// ServerSession enforces the server side of the initialization handshake and
+// records what was negotiated. It is safe for concurrent use.
+type ServerSession struct {
+	mu                 sync.Mutex
+	state              SessionState
+	protocolVersion    string
+	clientInfo         Implementation
+	clientCapabilities ClientCapabilities
+}
+
+func NewServerSession() *ServerSession {
+	return &ServerSession{
+		state: SessionUninitialized,
+	}
+}
+
+// State returns the current lifecycle state
+func (s *ServerSession) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// HandleInitialize records the client's initialize request and returns the
+// negotiated protocol version: the requested one if supported, otherwise the latest
+func (s *ServerSession) HandleInitialize(req *InitializeRequest) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("initialize request cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != SessionUninitialized {
+		return "", &ErrorInfo{
+			Code:    ErrInvalidRequest,
+			Message: fmt.Sprintf("Session already %s", s.state),
+		}
+	}
+
+	version := LatestProtocolVersion
+	for _, supported := range SupportedProtocolVersions {
+		if supported == req.Params.ProtocolVersion {
+			version = supported
+			break
+		}
+	}
+
+	s.protocolVersion = version
+	s.clientInfo = req.Params.ClientInfo
+	s.clientCapabilities = req.Params.Capabilities
+	s.state = SessionInitializing
+	return version, nil
+}
+
+// HandleInitialized marks the session ready after notifications/initialized
+func (s *ServerSession) HandleInitialized() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != SessionInitializing {
+		return fmt.Errorf("unexpected initialized notification: session is %s", s.state)
+	}
+
+	s.state = SessionReady
+	return nil
+}
+
+// CheckRequest returns a server-not-initialized error for requests other than
+// initialize and ping that arrive before the handshake completes
+func (s *ServerSession) CheckRequest(method string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if method == MethodInitialize || method == MethodPing || s.state == SessionReady {
+		return nil
+	}
+
+	return NewServerNotInitializedError()
+}
+
+// ProtocolVersion returns the negotiated protocol version
+func (s *ServerSession) ProtocolVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.protocolVersion
+}
+
+// ClientInfo returns the client implementation sent during initialize
+func (s *ServerSession) ClientInfo() Implementation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientInfo
+}
+
+// ClientCapabilities returns the capabilities the client advertised during initialize
+func (s *ServerSession) ClientCapabilities() ClientCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientCapabilities
+}
+
+/* Usage Example:
+func ExampleClientSession() {
+    session, err := NewClientSession(func(n *InitializedNotification) error {
+        return send(n) // write to the transport
+    })
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Requests are rejected until the handshake completes
+    if err := session.CheckRequest("tools/list"); err != nil {
+        fmt.Println(err) // cannot send tools/list: session is uninitialized
+    }
+
+    if err := session.BeginInitialize(); err != nil {
+        log.Fatal(err)
+    }
+    result, err := sendInitialize(request)
+    if err != nil {
+        session.AbortInitialize()
+        log.Fatal(err)
+    }
+
+    // Sends notifications/initialized and marks the session ready
+    if err := session.CompleteInitialize(result); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func ExampleServerSession() {
+    session := NewServerSession()
+
+    // Premature requests get a -32002 error
+    if err := session.CheckRequest("tools/call"); err != nil {
+        respondError(id, err.(*ErrorInfo))
+    }
+
+    version, err := session.HandleInitialize(request)
+    if err != nil {
+        log.Fatal(err)
+    }
+    // ...respond with an InitializeResult using version...
+
+    // On notifications/initialized
+    if err := session.HandleInitialized(); err != nil {
+        log.Fatal(err)
+    }
+
+    if session.ClientCapabilities().HasSampling() {
+        // the client can serve sampling/createMessage
+    }
+}
+*/
@@ -0,0 +1,312 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Levels beyond slog's four, spaced like the built-in ones
+const (
+	SlogLevelNotice    = slog.Level(2)
+	SlogLevelCritical  = slog.Level(12)
+	SlogLevelAlert     = slog.Level(16)
+	SlogLevelEmergency = slog.Level(20)
+)
+
+// SlogLevel maps a logging level to the equivalent slog level. Unknown levels
+// map to slog.LevelInfo.
+func SlogLevel(level LoggingLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelNotice:
+		return SlogLevelNotice
+	case LogLevelWarning:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelCritical:
+		return SlogLevelCritical
+	case LogLevelAlert:
+		return SlogLevelAlert
+	case LogLevelEmergency:
+		return SlogLevelEmergency
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LoggingLevelFromSlog maps a slog level to the most severe logging level it
+// reaches, so that slog.LevelWarn+1 is still a warning
+func LoggingLevelFromSlog(level slog.Level) LoggingLevel {
+	switch {
+	case level >= SlogLevelEmergency:
+		return LogLevelEmergency
+	case level >= SlogLevelAlert:
+		return LogLevelAlert
+	case level >= SlogLevelCritical:
+		return LogLevelCritical
+	case level >= slog.LevelError:
+		return LogLevelError
+	case level >= slog.LevelWarn:
+		return LogLevelWarning
+	case level >= SlogLevelNotice:
+		return LogLevelNotice
+	case level >= slog.LevelInfo:
+		return LogLevelInfo
+	default:
+		return LogLevelDebug
+	}
+}
+
+// SlogHandler passes log messages received from a peer to a slog.Handler.
+// A string payload becomes the record's message; an object payload's "msg"
+// field does, and its other fields become attributes. Any other payload is
+// kept under a "data" attribute. The logger name is added as "logger".
+type SlogHandler struct {
+	handler slog.Handler
+}
+
+func NewSlogHandler(handler slog.Handler) (*SlogHandler, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("slog handler cannot be nil")
+	}
+	return &SlogHandler{handler: handler}, nil
+}
+
+// Handle converts msg to a record and passes it on if the handler is enabled
+// at its level
+func (h *SlogHandler) Handle(ctx context.Context, msg *LoggingMessageNotification) error {
+	if msg == nil {
+		return fmt.Errorf("logging message cannot be nil")
+	}
+
+	level := SlogLevel(msg.Params.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	record := slog.NewRecord(time.Now(), level, "", 0)
+	switch data := msg.Params.Data.(type) {
+	case string:
+		record.Message = data
+	case map[string]interface{}:
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if text, ok := data[key].(string); ok && key == slog.MessageKey {
+				record.Message = text
+				continue
+			}
+			record.AddAttrs(slog.Any(key, data[key]))
+		}
+	default:
+		record.AddAttrs(slog.Any("data", data))
+	}
+	if msg.Params.Logger != nil {
+		record.AddAttrs(slog.String("logger", *msg.Params.Logger))
+	}
+
+	return h.handler.Handle(ctx, record)
+}
+
+// SlogNotifierOption configures SlogNotifier
+type SlogNotifierOption func(*SlogNotifier) error
+
+// SlogNotifier is a slog.Handler that sends each record as notifications/message.
+// The payload is an object holding the message under "msg" and the record's
+// attributes, with groups as nested objects.
+type SlogNotifier struct {
+	sink      func(*LoggingMessageNotification) error
+	threshold func() LoggingLevel
+	logger    *string
+	scopes    []slogScope // from WithGroup and WithAttrs, outermost first
+}
+
+// slogScope is either a group opened by WithGroup or attributes added by WithAttrs
+type slogScope struct {
+	group string
+	attrs []slog.Attr
+}
+
+func NewSlogNotifier(sink func(*LoggingMessageNotification) error, opts ...SlogNotifierOption) (*SlogNotifier, error) {
+	if sink == nil {
+		return nil, fmt.Errorf("slog notifier sink cannot be nil")
+	}
+
+	n := &SlogNotifier{
+		sink:      sink,
+		threshold: func() LoggingLevel { return LogLevelDebug },
+	}
+
+	for _, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, fmt.Errorf("applying slog notifier option: %w", err)
+		}
+	}
+
+	return n, nil
+}
+
+// SlogNotifier options
+
+// WithSlogLogger sets the logger name of every message
+func WithSlogLogger(name string) SlogNotifierOption {
+	return func(n *SlogNotifier) error {
+		if name == "" {
+			return fmt.Errorf("logger name cannot be empty")
+		}
+		n.logger = &name
+		return nil
+	}
+}
+
+// WithSlogThreshold drops records below level
+func WithSlogThreshold(level LoggingLevel) SlogNotifierOption {
+	return func(n *SlogNotifier) error {
+		if err := validateLoggingLevel(level); err != nil {
+			return err
+		}
+		n.threshold = func() LoggingLevel { return level }
+		return nil
+	}
+}
+
+// SlogHandler returns a slog.Handler that sends records to the logger's sink,
+// honouring its current level
+func (l *Logger) SlogHandler(opts ...SlogNotifierOption) (*SlogNotifier, error) {
+	n, err := NewSlogNotifier(l.sink, opts...)
+	if err != nil {
+		return nil, err
+	}
+	n.threshold = l.Level
+	return n, nil
+}
+
+func (n *SlogNotifier) Enabled(ctx context.Context, level slog.Level) bool {
+	return ShouldLog(n.threshold(), LoggingLevelFromSlog(level))
+}
+
+func (n *SlogNotifier) Handle(ctx context.Context, record slog.Record) error {
+	data := map[string]interface{}{slog.MessageKey: record.Message}
+
+	current := data
+	for _, scope := range n.scopes {
+		if scope.group != "" {
+			group := map[string]interface{}{}
+			current[scope.group] = group
+			current = group
+			continue
+		}
+		addSlogAttrs(current, scope.attrs)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttrs(current, []slog.Attr{attr})
+		return true
+	})
+
+	var opts []LoggingMessageOption
+	if n.logger != nil {
+		opts = append(opts, WithLogger(*n.logger))
+	}
+	msg, err := NewLoggingMessage(LoggingLevelFromSlog(record.Level), data, opts...)
+	if err != nil {
+		return err
+	}
+	return n.sink(msg)
+}
+
+func (n *SlogNotifier) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return n
+	}
+	return n.with(slogScope{attrs: attrs})
+}
+
+func (n *SlogNotifier) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return n
+	}
+	return n.with(slogScope{group: name})
+}
+
+func (n *SlogNotifier) with(scope slogScope) *SlogNotifier {
+	clone := *n
+	clone.scopes = append(append([]slogScope(nil), n.scopes...), scope)
+	return &clone
+}
+
+// addSlogAttrs adds attrs to m following slog's rules: empty attributes are
+// dropped and groups without a key are inlined
+func addSlogAttrs(m map[string]interface{}, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+
+		switch attr.Value.Kind() {
+		case slog.KindGroup:
+			group := attr.Value.Group()
+			if len(group) == 0 {
+				continue
+			}
+			if attr.Key == "" {
+				addSlogAttrs(m, group)
+				continue
+			}
+			nested := map[string]interface{}{}
+			addSlogAttrs(nested, group)
+			m[attr.Key] = nested
+		case slog.KindTime:
+			m[attr.Key] = attr.Value.Time().Format(time.RFC3339Nano)
+		case slog.KindDuration:
+			m[attr.Key] = attr.Value.Duration().String()
+		case slog.KindAny:
+			if err, ok := attr.Value.Any().(error); ok {
+				m[attr.Key] = err.Error()
+			} else {
+				m[attr.Key] = attr.Value.Any()
+			}
+		default:
+			m[attr.Key] = attr.Value.Any()
+		}
+	}
+}
+
+/* Usage Example:
+func ExampleSlogNotifier() {
+    // Server: send the application's slog output to the client as well as stderr
+    logger, _ := NewLogger(LogLevelInfo, func(msg *LoggingMessageNotification) error {
+        return emitter.Emit(msg)
+    })
+    notifier, err := logger.SlogHandler(WithSlogLogger("app"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    slog.New(notifier).With("request", 42).Warn("cache miss", "key", "users/7")
+    // {"method":"notifications/message","params":{"level":"warning","logger":"app",
+    //  "data":{"msg":"cache miss","request":42,"key":"users/7"}}}
+}
+
+func ExampleSlogHandler() {
+    // Client: write the server's log messages to the local slog handler
+    handler, _ := NewSlogHandler(slog.Default().Handler())
+    onNotification := func(method string, params json.RawMessage) {
+        if method != MethodLoggingMessage {
+            return
+        }
+        msg := &LoggingMessageNotification{Method: method}
+        if err := json.Unmarshal(params, &msg.Params); err != nil {
+            return
+        }
+        handler.Handle(ctx, msg)
+    }
+}
+*/
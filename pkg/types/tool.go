@@ -1,5 +1,13 @@
 package types
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
 // JSONSchemaType represents valid JSON Schema types
 type JSONSchemaType string
 
@@ -18,7 +26,7 @@ type SchemaEnum []interface{}
 
 // JSONSchema represents a JSON Schema object for tool input validation
 type JSONSchema struct {
-    Type       JSONSchemaType          `json:"type"`
+    Type       JSONSchemaType          `json:"type,omitempty"`
     Properties map[string]JSONSchema   `json:"properties,omitempty"`
     Required   []string               `json:"required,omitempty"`
     Items      *JSONSchema            `json:"items,omitempty"`
@@ -29,6 +37,19 @@ type JSONSchema struct {
     Minimum    *float64               `json:"minimum,omitempty"`
     Maximum    *float64               `json:"maximum,omitempty"`
     Pattern    *string                `json:"pattern,omitempty"`
+    Const      interface{}            `json:"const,omitempty"`
+    // Sensitive marks values that must be redacted from logs (extension keyword)
+    Sensitive  *bool                  `json:"x-sensitive,omitempty"`
+    // Ref points to a definition in the root schema's Defs, e.g. "#/$defs/page"
+    Ref        *string                `json:"$ref,omitempty"`
+    Defs       map[string]JSONSchema  `json:"$defs,omitempty"`
+    // AdditionalProperties set to false rejects object members not in Properties
+    AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+    // Annotation keywords; they describe values but do not affect validation
+    Schema      *string     `json:"$schema,omitempty"`
+    Title       *string     `json:"title,omitempty"`
+    Description *string     `json:"description,omitempty"`
+    Default     interface{} `json:"default,omitempty"`
 }
 
 // Common schema constructors
@@ -103,6 +124,21 @@ func WithPattern(pattern string) SchemaOption {
     }
 }
 
+// WithSensitive marks the value for redaction by RedactArguments
+func WithSensitive() SchemaOption {
+    return func(s *JSONSchema) {
+        sensitive := true
+        s.Sensitive = &sensitive
+    }
+}
+
+// WithConst pins the schema to a single allowed value
+func WithConst(value interface{}) SchemaOption {
+    return func(s *JSONSchema) {
+        s.Const = value
+    }
+}
+
 func WithNumberRange(min, max float64) SchemaOption {
     return func(s *JSONSchema) {
         s.Minimum = &min
@@ -125,7 +161,636 @@ func ObjectSchema(properties map[string]JSONSchema) JSONSchema {
     }
 }
 
-// Rest of the tool.go implementation remains the same, but now we can use these more type-safe schemas:
+// ToolOption configures a Tool
+type ToolOption func(*Tool) error
+
+// Tool represents a tool the server exposes to clients
+type Tool struct {
+    Name           string                 `json:"name"`
+    Description    *string                `json:"description,omitempty"`
+    InputSchema    JSONSchema             `json:"inputSchema"`
+    OutputSchema   *JSONSchema            `json:"outputSchema,omitempty"`
+    Annotations    *ToolAnnotations       `json:"annotations,omitempty"`
+    Deprecated     *DeprecationInfo       `json:"deprecated,omitempty"`
+    Examples       []ToolExample          `json:"examples,omitempty"`
+    // MaxConcurrency limits how many calls may run at once; nil means unlimited.
+    // It is enforced by ToolRegistry and never sent to clients.
+    MaxConcurrency *int                   `json:"-"`
+    Meta           map[string]interface{} `json:"_meta,omitempty"`
+
+    definitions map[string]JSONSchema
+}
+
+// ToolExample is an advisory sample invocation clients may show the model
+type ToolExample struct {
+    Description string                 `json:"description,omitempty"`
+    Arguments   map[string]interface{} `json:"arguments"`
+}
+
+// DeprecationInfo marks a tool that still works but should no longer be used
+type DeprecationInfo struct {
+    Message string  `json:"message"`
+    Since   *string `json:"since,omitempty"`
+}
+
+// ToolAnnotations describes tool behavior. All fields are hints and are not
+// guaranteed to be accurate.
+type ToolAnnotations struct {
+    Title           *string `json:"title,omitempty"`
+    ReadOnlyHint    *bool   `json:"readOnlyHint,omitempty"`    // defaults to false
+    DestructiveHint *bool   `json:"destructiveHint,omitempty"` // defaults to true
+    IdempotentHint  *bool   `json:"idempotentHint,omitempty"`  // defaults to false
+    OpenWorldHint   *bool   `json:"openWorldHint,omitempty"`   // defaults to true
+}
+
+func NewTool(name string, opts ...ToolOption) (*Tool, error) {
+    if name == "" {
+        return nil, fmt.Errorf("tool name cannot be empty")
+    }
+
+    t := &Tool{
+        Name:        name,
+        InputSchema: ObjectSchema(make(map[string]JSONSchema)),
+    }
+
+    for _, opt := range opts {
+        if err := opt(t); err != nil {
+            return nil, fmt.Errorf("applying tool option: %w", err)
+        }
+    }
+
+    if err := t.collectDefinitions(); err != nil {
+        return nil, err
+    }
+
+    // Required names are checked once all properties have been added
+    for _, name := range t.InputSchema.Required {
+        if _, ok := t.InputSchema.Properties[name]; !ok {
+            return nil, fmt.Errorf("required property %s is not defined", name)
+        }
+    }
+
+    // Examples must stay in sync with the final schema. They are checked in
+    // the form clients will see, so Go values such as []string validate like
+    // the JSON arrays they encode to.
+    for i, example := range t.Examples {
+        raw, err := json.Marshal(example.Arguments)
+        if err != nil {
+            return nil, fmt.Errorf("encoding example %d: %w", i, err)
+        }
+        if _, err := DecodeArguments(raw, &t.InputSchema); err != nil {
+            return nil, fmt.Errorf("example %d does not match input schema: %w", i, err)
+        }
+    }
+
+    return t, nil
+}
+
+// Tool options
+
+func WithToolDescription(description string) ToolOption {
+    return func(t *Tool) error {
+        t.Description = &description
+        return nil
+    }
+}
+
+func WithToolProperty(name string, schema JSONSchema) ToolOption {
+    return func(t *Tool) error {
+        if name == "" {
+            return fmt.Errorf("property name cannot be empty")
+        }
+        t.InputSchema.Properties[name] = schema
+        return nil
+    }
+}
+
+// WithToolPropertyRef adds a property that references a shared definition
+// instead of inlining it. refPath has the form "#/$defs/<name>"; NewTool copies
+// the definition from WithToolDefinitions into the input schema's $defs.
+func WithToolPropertyRef(name, refPath string) ToolOption {
+    return func(t *Tool) error {
+        if name == "" {
+            return fmt.Errorf("property name cannot be empty")
+        }
+        if _, err := refDefName(refPath); err != nil {
+            return err
+        }
+        t.InputSchema.Properties[name] = JSONSchema{Ref: &refPath}
+        return nil
+    }
+}
+
+// WithToolDefinitions supplies shared definitions, such as pagination or filter
+// shapes used by several tools. Only definitions the input schema references,
+// directly or through other definitions, end up in its $defs.
+func WithToolDefinitions(defs map[string]JSONSchema) ToolOption {
+    return func(t *Tool) error {
+        if t.definitions == nil {
+            t.definitions = make(map[string]JSONSchema, len(defs))
+        }
+        for name, def := range defs {
+            t.definitions[name] = def
+        }
+        return nil
+    }
+}
+
+// collectDefinitions copies every definition reachable from the input schema's
+// $refs into its $defs. Definitions already in $defs take precedence.
+func (t *Tool) collectDefinitions() error {
+    var visit func(s JSONSchema) error
+    visit = func(s JSONSchema) error {
+        if s.Ref != nil {
+            name, err := refDefName(*s.Ref)
+            if err != nil {
+                return err
+            }
+            if _, ok := t.InputSchema.Defs[name]; !ok {
+                def, ok := t.definitions[name]
+                if !ok {
+                    return fmt.Errorf("undefined definition %s referenced by %s", name, *s.Ref)
+                }
+                if t.InputSchema.Defs == nil {
+                    t.InputSchema.Defs = make(map[string]JSONSchema)
+                }
+                t.InputSchema.Defs[name] = def
+                if err := visit(def); err != nil {
+                    return err
+                }
+            }
+        }
+        for _, prop := range s.Properties {
+            if err := visit(prop); err != nil {
+                return err
+            }
+        }
+        if s.Items != nil {
+            return visit(*s.Items)
+        }
+        return nil
+    }
+
+    root := t.InputSchema
+    if err := visit(root); err != nil {
+        return err
+    }
+    for _, def := range root.Defs {
+        if err := visit(def); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// WithToolRequired marks properties as required. Duplicate names are dropped,
+// keeping the order in which names were first seen.
+func WithToolRequired(names ...string) ToolOption {
+    return func(t *Tool) error {
+        for _, name := range names {
+            if !containsString(t.InputSchema.Required, name) {
+                t.InputSchema.Required = append(t.InputSchema.Required, name)
+            }
+        }
+        return nil
+    }
+}
+
+func containsString(values []string, target string) bool {
+    for _, v := range values {
+        if v == target {
+            return true
+        }
+    }
+    return false
+}
+
+func WithToolAnnotations(annotations *ToolAnnotations) ToolOption {
+    return func(t *Tool) error {
+        t.Annotations = annotations
+        return nil
+    }
+}
+
+// WithToolInputSchemaJSON replaces the input schema with one decoded from raw
+// JSON, such as a schema file. The top-level type must be object. Validation
+// keywords JSONSchema does not implement, such as anyOf or multipleOf, are
+// rejected rather than silently dropped; other unknown keywords are annotations
+// and are ignored. Options applied afterwards, like WithToolProperty, extend
+// the loaded schema.
+func WithToolInputSchemaJSON(raw []byte) ToolOption {
+    return func(t *Tool) error {
+        if err := checkSchemaKeywords(raw); err != nil {
+            return fmt.Errorf("decoding input schema: %w", err)
+        }
+        var schema JSONSchema
+        if err := json.Unmarshal(raw, &schema); err != nil {
+            return fmt.Errorf("decoding input schema: %w", err)
+        }
+        if schema.Type != TypeObject {
+            return fmt.Errorf("input schema must have type object, got %q", schema.Type)
+        }
+        if _, err := schema.Compile(); err != nil {
+            return fmt.Errorf("invalid input schema: %w", err)
+        }
+        if schema.Properties == nil {
+            schema.Properties = make(map[string]JSONSchema)
+        }
+        t.InputSchema = schema
+        return nil
+    }
+}
+
+// WithToolOutputSchema advertises the shape of the tool's structured content
+func WithToolOutputSchema(schema JSONSchema) ToolOption {
+    return func(t *Tool) error {
+        if schema.Type != TypeObject {
+            return fmt.Errorf("output schema must have type object, got %q", schema.Type)
+        }
+        t.OutputSchema = &schema
+        return nil
+    }
+}
+
+// WithToolDeprecated marks the tool deprecated; msg should point users to a replacement
+func WithToolDeprecated(msg string) ToolOption {
+    return func(t *Tool) error {
+        if msg == "" {
+            return fmt.Errorf("deprecation message cannot be empty")
+        }
+        t.Deprecated = &DeprecationInfo{Message: msg}
+        return nil
+    }
+}
+
+// WithToolExample attaches an example invocation. Examples are validated
+// against the input schema once all options have been applied.
+func WithToolExample(description string, arguments map[string]interface{}) ToolOption {
+    return func(t *Tool) error {
+        if arguments == nil {
+            arguments = map[string]interface{}{}
+        }
+        t.Examples = append(t.Examples, ToolExample{
+            Description: description,
+            Arguments:   arguments,
+        })
+        return nil
+    }
+}
+
+// WithToolConcurrency limits the tool to n concurrent calls; use 1 for tools
+// that must be serialized. ToolRegistry enforces the limit.
+func WithToolConcurrency(n int) ToolOption {
+    return func(t *Tool) error {
+        if n <= 0 {
+            return fmt.Errorf("max concurrency must be positive")
+        }
+        t.MaxConcurrency = &n
+        return nil
+    }
+}
+
+// WithToolMeta sets a server-specific _meta entry on the tool, such as
+// rate-limit hints, auth scopes or internal IDs
+func WithToolMeta(key string, value interface{}) ToolOption {
+    return func(t *Tool) error {
+        if key == "" {
+            return fmt.Errorf("meta key cannot be empty")
+        }
+        if t.Meta == nil {
+            t.Meta = make(map[string]interface{})
+        }
+        t.Meta[key] = value
+        return nil
+    }
+}
+
+// IsDeprecated reports whether the tool is marked deprecated
+func (t *Tool) IsDeprecated() bool {
+    return t.Deprecated != nil
+}
+
+// RequiresConfirmation reports whether a tool may modify its environment
+// destructively and should only run after the user confirmed the call.
+// Missing hints fall back to the spec defaults (not read-only, destructive).
+func RequiresConfirmation(tool Tool) bool {
+    a := tool.Annotations
+    if a == nil {
+        return true
+    }
+    if a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+        return false
+    }
+    return a.DestructiveHint == nil || *a.DestructiveHint
+}
+
+const (
+    MethodCallTool = "tools/call"
+
+    // MetaKeyUserConfirmed marks a tool call the user has explicitly approved
+    MetaKeyUserConfirmed = "userConfirmed"
+)
+
+// CallToolRequestOption configures CallToolRequest
+type CallToolRequestOption func(*CallToolRequest) error
+
+// CallToolRequest represents a request to invoke a tool
+type CallToolRequest struct {
+    Method string         `json:"method"`
+    Params CallToolParams `json:"params"`
+}
+
+type CallToolParams struct {
+    Name      string                 `json:"name"`
+    Arguments map[string]interface{} `json:"arguments,omitempty"`
+    Meta      map[string]interface{} `json:"_meta,omitempty"`
+}
+
+func NewCallToolRequest(name string, arguments map[string]interface{}, opts ...CallToolRequestOption) (*CallToolRequest, error) {
+    if name == "" {
+        return nil, fmt.Errorf("tool name cannot be empty")
+    }
+
+    req := &CallToolRequest{
+        Method: MethodCallTool,
+        Params: CallToolParams{
+            Name:      name,
+            Arguments: arguments,
+        },
+    }
+
+    for _, opt := range opts {
+        if err := opt(req); err != nil {
+            return nil, fmt.Errorf("applying call tool request option: %w", err)
+        }
+    }
+
+    return req, nil
+}
+
+// CallToolRequest options
+
+// WithUserConfirmation records that the client obtained user confirmation for the call
+func WithUserConfirmation() CallToolRequestOption {
+    return func(r *CallToolRequest) error {
+        if r.Params.Meta == nil {
+            r.Params.Meta = make(map[string]interface{})
+        }
+        r.Params.Meta[MetaKeyUserConfirmed] = true
+        return nil
+    }
+}
+
+// IsConfirmed reports whether the request carries user confirmation
+func (r *CallToolRequest) IsConfirmed() bool {
+    confirmed, ok := r.Params.Meta[MetaKeyUserConfirmed].(bool)
+    return ok && confirmed
+}
+
+// ShouldConfirm reports whether a call must be held for user approval before
+// dispatch: the tool requires confirmation and the request does not carry it.
+// An idempotent hint does not waive confirmation, since repeating a destructive
+// call does not undo it. A request naming a different tool is always held.
+func ShouldConfirm(req CallToolRequest, tool Tool) bool {
+    if req.Params.Name != tool.Name {
+        return true
+    }
+    return RequiresConfirmation(tool) && !req.IsConfirmed()
+}
+
+// CallToolResult represents the result of a tool invocation
+type CallToolResult struct {
+    Content           []Content              `json:"content"`
+    StructuredContent map[string]interface{} `json:"structuredContent,omitempty"`
+    IsError           *bool                  `json:"isError,omitempty"`
+}
+
+// ValidateAgainst checks the structured content against a tool's output schema,
+// collecting all failures into a ValidationError. Paths are relative to the
+// structured content. A nil schema accepts any result.
+func (r *CallToolResult) ValidateAgainst(schema *JSONSchema) error {
+    if schema == nil {
+        return nil
+    }
+    cs, err := schema.Compile()
+    if err != nil {
+        return fmt.Errorf("compiling output schema: %w", err)
+    }
+    return r.validateStructured(cs)
+}
+
+func (r *CallToolResult) validateStructured(cs *CompiledSchema) error {
+    if r.StructuredContent == nil {
+        return ValidationError{Validation: []ValidationFailure{
+            {Field: "structuredContent", Error: "required by the output schema"},
+        }}
+    }
+    return cs.Validate(r.StructuredContent)
+}
+
+// ListToolsResult represents the response to a list tools request
+type ListToolsResult struct {
+    NextCursor *string `json:"nextCursor,omitempty"`
+    Tools      []Tool  `json:"tools"`
+}
+
+// Helper constructors for common tool result shapes
+
+func NewToolResultText(text string) *CallToolResult {
+    return &CallToolResult{
+        Content: []Content{*NewTextContent(text, nil)},
+    }
+}
+
+// NewToolResultJSON marshals v as indented JSON into a text content block.
+// When v encodes to a JSON object it is also set as the structured content,
+// so the result can be checked against the tool's output schema.
+func NewToolResultJSON(v interface{}) (*CallToolResult, error) {
+    data, err := json.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return nil, fmt.Errorf("marshaling tool result: %w", err)
+    }
+
+    result := NewToolResultText(string(data))
+    if len(data) > 0 && data[0] == '{' {
+        dec := json.NewDecoder(bytes.NewReader(data))
+        dec.UseNumber()
+        if err := dec.Decode(&result.StructuredContent); err != nil {
+            return nil, fmt.Errorf("decoding structured tool result: %w", err)
+        }
+    }
+    return result, nil
+}
+
+// NewToolResultResource embeds resource contents in a result, e.g. the file a
+// tool read. The content must hold exactly one of text or blob and, if it has
+// a checksum, match it.
+func NewToolResultResource(rc *ResourceContent) (*CallToolResult, error) {
+    if rc == nil {
+        return nil, fmt.Errorf("resource content cannot be nil")
+    }
+    if rc.URI == "" {
+        return nil, fmt.Errorf("resource URI cannot be empty")
+    }
+    if (rc.Text != nil) == (rc.Blob != nil) {
+        return nil, fmt.Errorf("exactly one of text or blob must be set")
+    }
+    if rc.Encoding != nil && rc.Blob == nil {
+        return nil, fmt.Errorf("encoding requires blob content")
+    }
+    if err := rc.VerifyChecksum(); err != nil {
+        return nil, err
+    }
+
+    content := Content{Type: ContentTypeResource, ResourceContent: rc}
+    if err := content.Validate(); err != nil {
+        return nil, fmt.Errorf("invalid resource content: %w", err)
+    }
+    return &CallToolResult{Content: []Content{content}}, nil
+}
+
+// NewToolResultError reports a tool failure to the model as a result rather
+// than a protocol error
+func NewToolResultError(msg string) *CallToolResult {
+    isError := true
+    return &CallToolResult{
+        Content: []Content{*NewTextContent(msg, nil)},
+        IsError: &isError,
+    }
+}
+
+const MethodPartialToolResult = "notifications/tools/partialResult"
+
+// PartialCallToolResult carries content produced by a tool before it finishes.
+// Chunks belong to the call whose request carried ProgressToken in its _meta,
+// and Sequence numbers them from 1 in emission order. The final CallToolResult
+// is still returned as the call's response and closes the stream.
+type PartialCallToolResult struct {
+    ProgressToken ProgressToken `json:"progressToken"`
+    Sequence      int           `json:"sequence"`
+    Content       []Content     `json:"content"`
+}
+
+// PartialToolResultNotification forwards a partial result to the client
+type PartialToolResultNotification struct {
+    Method string                `json:"method"`
+    Params PartialCallToolResult `json:"params"`
+}
+
+// ToolResultStream lets a tool handler emit partial content while it runs.
+// The server drains Chunks and forwards each notification to the client; once
+// the channel is closed, Result holds the final result.
+type ToolResultStream struct {
+    mu     sync.Mutex
+    token  ProgressToken
+    seq    int
+    closed bool
+    result *CallToolResult
+
+    turn   chan struct{} // held by the one Send in progress, keeping chunks in sequence order
+    done   chan struct{} // closed by Close to abort a Send waiting for the reader
+    chunks chan *PartialToolResultNotification
+}
+
+func NewToolResultStream(token ProgressToken, buffer int) (*ToolResultStream, error) {
+    if buffer < 0 {
+        return nil, fmt.Errorf("buffer size cannot be negative")
+    }
+
+    return &ToolResultStream{
+        token:  token,
+        turn:   make(chan struct{}, 1),
+        done:   make(chan struct{}),
+        chunks: make(chan *PartialToolResultNotification, buffer),
+    }, nil
+}
+
+// Chunks returns the channel of partial result notifications
+func (s *ToolResultStream) Chunks() <-chan *PartialToolResultNotification {
+    return s.chunks
+}
+
+// Send emits content blocks as the next partial result. It blocks until the
+// chunk is buffered, ctx is done or the stream is closed.
+func (s *ToolResultStream) Send(ctx context.Context, content ...Content) error {
+    if len(content) == 0 {
+        return fmt.Errorf("partial result content cannot be empty")
+    }
+
+    select {
+    case s.turn <- struct{}{}:
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+    defer func() { <-s.turn }()
+
+    s.mu.Lock()
+    if s.closed {
+        s.mu.Unlock()
+        return fmt.Errorf("tool result stream is closed")
+    }
+    s.seq++
+    n := &PartialToolResultNotification{
+        Method: MethodPartialToolResult,
+        Params: PartialCallToolResult{
+            ProgressToken: s.token,
+            Sequence:      s.seq,
+            Content:       content,
+        },
+    }
+    s.mu.Unlock()
+
+    select {
+    case s.chunks <- n:
+        return nil
+    case <-ctx.Done():
+        s.unsend()
+        return ctx.Err()
+    case <-s.done:
+        s.unsend()
+        return fmt.Errorf("tool result stream is closed")
+    }
+}
+
+// unsend gives back the sequence number of a chunk that was never delivered.
+// The caller holds the turn, so no other chunk has taken a later number.
+func (s *ToolResultStream) unsend() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.seq--
+}
+
+// Close ends the stream with the final result. A Send still waiting for the
+// reader fails.
+func (s *ToolResultStream) Close(result *CallToolResult) error {
+    if result == nil {
+        return fmt.Errorf("final result cannot be nil")
+    }
+
+    s.mu.Lock()
+    if s.closed {
+        s.mu.Unlock()
+        return fmt.Errorf("tool result stream is already closed")
+    }
+    s.closed = true
+    s.result = result
+    close(s.done)
+    s.mu.Unlock()
+
+    // wait out a Send in progress before closing the channel it sends on
+    s.turn <- struct{}{}
+    close(s.chunks)
+    <-s.turn
+    return nil
+}
+
+// Result returns the final result, or nil if the stream is still open
+func (s *ToolResultStream) Result() *CallToolResult {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.result
+}
+
 
 /* Usage Example:
 func ExampleToolWithSchema() {
@@ -144,11 +809,58 @@ func ExampleToolWithSchema() {
         }),
         WithToolProperty("cpu", NewNumberEnum(0.5, 1.0, 2.0)),
         WithToolRequired("name", "environment"),
+        WithToolAnnotations(&ToolAnnotations{
+            DestructiveHint: ptr(false),
+            IdempotentHint:  ptr(true),
+        }),
+        WithToolMeta("authScope", "deploy:write"),
+        WithToolExample("Scale the API in staging", map[string]interface{}{
+            "name":        "api",
+            "environment": "staging",
+            "replicas":    3,
+        }),
     )
     if err != nil {
         log.Fatal(err)
     }
 
+    // Destructive tools should only run with user confirmation
+    request, err := NewCallToolRequest("deployService", map[string]interface{}{
+        "name":        "api",
+        "environment": "prod",
+    }, WithUserConfirmation())
+    if err != nil {
+        log.Fatal(err)
+    }
+    if ShouldConfirm(*request, *deployTool) {
+        log.Fatal("tool call requires user confirmation")
+    }
+
+    // Shared argument shapes referenced instead of inlined in every tool
+    shared := map[string]JSONSchema{
+        "pagination": ObjectSchema(map[string]JSONSchema{
+            "cursor": StringSchema,
+            "limit":  IntegerSchema,
+        }),
+    }
+    listTool, err := NewTool("listDeployments",
+        WithToolDefinitions(shared),
+        WithToolPropertyRef("page", "#/$defs/pagination"),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // Tool definitions loaded from an external schema file
+    raw, err := os.ReadFile("schemas/search.json")
+    if err != nil {
+        log.Fatal(err)
+    }
+    searchTool, err := NewTool("search", WithToolInputSchemaJSON(raw))
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Example of complex nested schema
     serviceSchema := ObjectSchema(map[string]JSONSchema{
         "name": StringSchemaWithConstraints(
@@ -162,6 +874,14 @@ func ExampleToolWithSchema() {
         "environment": NewStringEnum("dev", "staging", "prod"),
     })
 
+    // A tool that returns the file it read as an embedded resource
+    text := string(data)
+    fileContent, _ := NewResourceContent("file:///project/main.go", WithContentText(text))
+    fileResult, err := NewToolResultResource(fileContent)
+    if err != nil {
+        log.Fatal(err)
+    }
+
     // Helper functions for creating pointers
     func ptr[T any](v T) *T {
         return &v
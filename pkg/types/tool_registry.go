@@ -0,0 +1,338 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ToolHandler is implemented by servers to execute a tool's calls
+type ToolHandler interface {
+	CallTool(ctx context.Context, params CallToolParams) (*CallToolResult, error)
+}
+
+// ToolHandlerFunc adapts a function to the ToolHandler interface
+type ToolHandlerFunc func(ctx context.Context, params CallToolParams) (*CallToolResult, error)
+
+func (f ToolHandlerFunc) CallTool(ctx context.Context, params CallToolParams) (*CallToolResult, error) {
+	return f(ctx, params)
+}
+
+// DefaultToolPageSize is how many tools List returns per page
+const DefaultToolPageSize = 100
+
+// ToolRegistryOption configures ToolRegistry
+type ToolRegistryOption func(*ToolRegistry) error
+
+// ToolRegistry routes tools/call requests to registered handlers. Arguments are
+// validated against the tool's input schema before the handler runs, successful
+// results against its output schema afterwards, and a tool's MaxConcurrency is
+// enforced across calls. It is safe for concurrent use.
+type ToolRegistry struct {
+	mu             sync.RWMutex
+	tools          map[string]*registeredTool
+	rejectOnBusy   bool
+	defaultTimeout time.Duration // zero means no limit
+	pageSize       int
+	compileOpts    []CompileOption
+}
+
+type registeredTool struct {
+	tool    Tool
+	schema  *CompiledSchema
+	output  *CompiledSchema // nil when the tool has no output schema
+	handler ToolHandler
+	slots   chan struct{}  // nil when concurrency is unlimited
+	timeout *time.Duration // overrides the registry default when set
+}
+
+// ToolRegistrationOption configures a single tool's registration
+type ToolRegistrationOption func(*registeredTool) error
+
+// errToolTimeout is the cause of a handler context whose timeout expired
+var errToolTimeout = errors.New("tool call timed out")
+
+func NewToolRegistry(opts ...ToolRegistryOption) (*ToolRegistry, error) {
+	r := &ToolRegistry{
+		tools:    make(map[string]*registeredTool),
+		pageSize: DefaultToolPageSize,
+	}
+
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, fmt.Errorf("applying tool registry option: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// ToolRegistry options
+
+// WithConcurrencyRejection fails calls that would exceed a tool's MaxConcurrency
+// instead of queueing them until a running call finishes
+func WithConcurrencyRejection() ToolRegistryOption {
+	return func(r *ToolRegistry) error {
+		r.rejectOnBusy = true
+		return nil
+	}
+}
+
+// WithDefaultToolTimeout limits how long any tool's handler may run unless
+// the tool was registered with WithToolTimeout
+func WithDefaultToolTimeout(d time.Duration) ToolRegistryOption {
+	return func(r *ToolRegistry) error {
+		if d <= 0 {
+			return fmt.Errorf("default tool timeout must be positive")
+		}
+		r.defaultTimeout = d
+		return nil
+	}
+}
+
+// WithToolPageSize sets how many tools List returns per page
+func WithToolPageSize(n int) ToolRegistryOption {
+	return func(r *ToolRegistry) error {
+		if n <= 0 {
+			return fmt.Errorf("tool page size must be positive")
+		}
+		r.pageSize = n
+		return nil
+	}
+}
+
+// WithValidationMessages renders the argument and output validation failures
+// of every tool with f, e.g. to localize them
+func WithValidationMessages(f MessageFormatter) ToolRegistryOption {
+	return func(r *ToolRegistry) error {
+		if f == nil {
+			return fmt.Errorf("message formatter cannot be nil")
+		}
+		r.compileOpts = append(r.compileOpts, WithMessageFormatter(f))
+		return nil
+	}
+}
+
+// Registration options
+
+// WithToolTimeout limits how long this tool's handler may run, overriding the
+// registry default. Zero removes the limit for the tool.
+func WithToolTimeout(d time.Duration) ToolRegistrationOption {
+	return func(rt *registeredTool) error {
+		if d < 0 {
+			return fmt.Errorf("tool timeout cannot be negative")
+		}
+		rt.timeout = &d
+		return nil
+	}
+}
+
+// Register adds a tool and its handler, replacing any tool with the same name
+func (r *ToolRegistry) Register(tool Tool, handler ToolHandler, opts ...ToolRegistrationOption) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool handler cannot be nil")
+	}
+
+	schema, err := tool.InputSchema.Compile(r.compileOpts...)
+	if err != nil {
+		return fmt.Errorf("compiling input schema for tool %s: %w", tool.Name, err)
+	}
+
+	rt := &registeredTool{tool: tool, schema: schema, handler: handler}
+	if tool.OutputSchema != nil {
+		rt.output, err = tool.OutputSchema.Compile(r.compileOpts...)
+		if err != nil {
+			return fmt.Errorf("compiling output schema for tool %s: %w", tool.Name, err)
+		}
+	}
+	if tool.MaxConcurrency != nil {
+		if *tool.MaxConcurrency <= 0 {
+			return fmt.Errorf("max concurrency for tool %s must be positive", tool.Name)
+		}
+		rt.slots = make(chan struct{}, *tool.MaxConcurrency)
+	}
+	for _, opt := range opts {
+		if err := opt(rt); err != nil {
+			return fmt.Errorf("applying registration option for tool %s: %w", tool.Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.Name] = rt
+	return nil
+}
+
+// Tools returns the registered tools ordered by name
+func (r *ToolRegistry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(r.tools))
+	for _, rt := range r.tools {
+		tools = append(tools, rt.tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// List returns a page of the registered tools that pass filter, ordered by
+// name, for answering tools/list. A nil filter keeps every tool and a nil
+// cursor starts at the first. Filtering happens before paging, and the cursor
+// names the last tool returned, so pages stay consistent when tools are
+// registered or hidden between requests. An invalid cursor yields an invalid
+// params error.
+func (r *ToolRegistry) List(cursor *string, filter func(Tool) bool) (*ListToolsResult, error) {
+	var after string
+	if cursor != nil {
+		name, err := base64.RawURLEncoding.DecodeString(*cursor)
+		if err != nil || len(name) == 0 {
+			return nil, &ErrorInfo{
+				Code:    ErrInvalidParams,
+				Message: fmt.Sprintf("Invalid cursor: %q", *cursor),
+			}
+		}
+		after = string(name)
+	}
+
+	result := &ListToolsResult{Tools: []Tool{}}
+	for _, tool := range r.Tools() {
+		if cursor != nil && tool.Name <= after {
+			continue
+		}
+		if filter != nil && !filter(tool) {
+			continue
+		}
+		if len(result.Tools) == r.pageSize {
+			next := base64.RawURLEncoding.EncodeToString([]byte(result.Tools[len(result.Tools)-1].Name))
+			result.NextCursor = &next
+			break
+		}
+		result.Tools = append(result.Tools, tool)
+	}
+	return result, nil
+}
+
+// Call handles a tools/call request. Unknown tools and arguments that fail the
+// input schema yield an invalid params error. When the tool is already running
+// MaxConcurrency calls, Call waits for a free slot until ctx is done, or fails
+// immediately if the registry was created with WithConcurrencyRejection. A
+// successful result whose structured content does not match the output schema
+// is reported as a tool execution error of type "invalidOutput". A handler
+// still running when its timeout expires has its context cancelled and the
+// call fails with a tool execution error of type "timeout".
+func (r *ToolRegistry) Call(ctx context.Context, req *CallToolRequest) (*CallToolResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("call tool request cannot be nil")
+	}
+
+	r.mu.RLock()
+	rt, ok := r.tools[req.Params.Name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, &ErrorInfo{
+			Code:    ErrInvalidParams,
+			Message: fmt.Sprintf("Unknown tool: %s", req.Params.Name),
+		}
+	}
+
+	var args interface{} = req.Params.Arguments
+	if req.Params.Arguments == nil {
+		args = map[string]interface{}{}
+	}
+	if err := rt.schema.Validate(args); err != nil {
+		var verr ValidationError
+		if errors.As(err, &verr) {
+			return nil, NewValidationError(verr.Validation)
+		}
+		return nil, err
+	}
+
+	if rt.slots != nil {
+		if r.rejectOnBusy {
+			select {
+			case rt.slots <- struct{}{}:
+			default:
+				return nil, NewToolExecutionError(rt.tool.Name, "concurrencyLimit",
+					fmt.Sprintf("tool already running %d concurrent calls", cap(rt.slots)))
+			}
+		} else {
+			select {
+			case rt.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting to call tool %s: %w", rt.tool.Name, ctx.Err())
+			}
+		}
+		defer func() { <-rt.slots }()
+	}
+
+	timeout := r.defaultTimeout
+	if rt.timeout != nil {
+		timeout = *rt.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errToolTimeout)
+		defer cancel()
+	}
+
+	result, err := rt.handler.CallTool(ctx, req.Params)
+	if context.Cause(ctx) == errToolTimeout {
+		return nil, NewToolExecutionError(rt.tool.Name, "timeout",
+			fmt.Sprintf("tool did not finish within %s", timeout))
+	}
+	if err != nil || result == nil || rt.output == nil {
+		return result, err
+	}
+	if result.IsError != nil && *result.IsError {
+		// error results describe the failure in Content and carry no structured output
+		return result, nil
+	}
+	if err := result.validateStructured(rt.output); err != nil {
+		return nil, NewToolExecutionError(rt.tool.Name, "invalidOutput", err.Error())
+	}
+	return result, nil
+}
+
+/* Usage Example:
+func ExampleToolRegistry() {
+    tool, err := NewTool("reindex",
+        WithToolDescription("Rebuild the search index"),
+        WithToolConcurrency(1), // reindexing must not overlap
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    registry, err := NewToolRegistry(
+        WithConcurrencyRejection(),
+        WithDefaultToolTimeout(30*time.Second),
+    )
+    if err != nil {
+        log.Fatal(err)
+    }
+    registry.Register(*tool, ToolHandlerFunc(func(ctx context.Context, params CallToolParams) (*CallToolResult, error) {
+        if err := reindex(ctx); err != nil {
+            return nil, err
+        }
+        return &CallToolResult{Content: []Content{*NewTextContent("done", nil)}}, nil
+    }), WithToolTimeout(10*time.Minute)) // reindexing takes longer than most tools
+
+    // A second reindex while the first is running fails with a
+    // toolExecution error of type "concurrencyLimit"
+    result, err := registry.Call(ctx, request)
+
+    // Answer tools/list without the deprecated tools
+    page, err := registry.List(params.Cursor, func(tool Tool) bool {
+        return tool.Deprecated == nil
+    })
+}
+*/
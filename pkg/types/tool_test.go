@@ -0,0 +1,170 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewToolResultJSONStructuredContent(t *testing.T) {
+	result, err := NewToolResultJSON(struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}{"widgets", 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StructuredContent["name"] != "widgets" || result.StructuredContent["count"] != json.Number("3") {
+		t.Errorf("structured content = %v", result.StructuredContent)
+	}
+
+	for _, v := range []interface{}{[]int{1, 2}, "text", nil} {
+		result, err := NewToolResultJSON(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StructuredContent != nil {
+			t.Errorf("NewToolResultJSON(%#v) set structured content %v", v, result.StructuredContent)
+		}
+	}
+}
+
+func TestWithToolInputSchemaJSON(t *testing.T) {
+	raw := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Search",
+		"type": "object",
+		"properties": {
+			"q": {"type": "string", "description": "Search query", "format": "text"},
+			"limit": {"type": "integer", "default": 10}
+		},
+		"required": ["q"],
+		"additionalProperties": false
+	}`
+	tool, err := NewTool("search", WithToolInputSchemaJSON([]byte(raw)))
+	if err != nil {
+		t.Fatalf("NewTool: %v", err)
+	}
+
+	schema := tool.InputSchema
+	if schema.Properties["q"].Description == nil || *schema.Properties["q"].Description != "Search query" {
+		t.Errorf("description was not kept: %+v", schema.Properties["q"])
+	}
+	if err := schema.Validate(map[string]interface{}{"q": "go", "limit": 5}); err != nil {
+		t.Errorf("valid arguments: %v", err)
+	}
+	if err := schema.Validate(map[string]interface{}{"q": "go", "page": 2}); err == nil {
+		t.Error("additionalProperties false should reject an unknown member")
+	}
+}
+
+func TestWithToolInputSchemaJSONRejectsUnsupportedKeywords(t *testing.T) {
+	tests := map[string]string{
+		"top level":             `{"type":"object","anyOf":[{"required":["a"]}]}`,
+		"nested":                `{"type":"object","properties":{"n":{"type":"integer","multipleOf":2}}}`,
+		"schema-valued members": `{"type":"object","additionalProperties":{"type":"string"}}`,
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewTool("t", WithToolInputSchemaJSON([]byte(raw))); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestToolMaxConcurrencyNotSerialized(t *testing.T) {
+	tool, err := NewTool("t", WithToolConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "oncurrency") {
+		t.Errorf("tool marshaled as %s", data)
+	}
+}
+
+func TestNewToolExamplesWithGoValues(t *testing.T) {
+	_, err := NewTool("tag",
+		WithToolProperty("tags", ArraySchema(StringSchema)),
+		WithToolProperty("labels", ObjectSchema(map[string]JSONSchema{"env": StringSchema})),
+		WithToolExample("tag a build", map[string]interface{}{
+			"tags":   []string{"release", "v2"},
+			"labels": map[string]string{"env": "prod"},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewTool: %v", err)
+	}
+
+	_, err = NewTool("tag",
+		WithToolProperty("tags", ArraySchema(StringSchema)),
+		WithToolExample("wrong item type", map[string]interface{}{"tags": []int{1}}),
+	)
+	if err == nil {
+		t.Error("expected an example with integer tags to fail")
+	}
+}
+
+func TestToolResultStreamCloseDoesNotWaitForReader(t *testing.T) {
+	stream, err := NewToolResultStream(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nobody reads the chunks, so the send waits until Close
+	sent := make(chan error, 1)
+	go func() {
+		sent <- stream.Send(context.Background(), *NewTextContent("partial", nil))
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- stream.Close(NewToolResultText("done"))
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked behind a pending Send")
+	}
+	if err := <-sent; err == nil {
+		t.Error("a send cut off by Close should fail")
+	}
+	if _, ok := <-stream.Chunks(); ok {
+		t.Error("chunks should be closed")
+	}
+	if stream.Result() == nil {
+		t.Error("Result should hold the final result")
+	}
+}
+
+func TestToolResultStreamSendHonoursContext(t *testing.T) {
+	stream, err := NewToolResultStream(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := stream.Send(ctx, *NewTextContent("lost", nil)); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the undelivered chunk gives its sequence number back
+	go func() {
+		_ = stream.Send(context.Background(), *NewTextContent("kept", nil))
+	}()
+	n := <-stream.Chunks()
+	if n.Params.Sequence != 1 {
+		t.Errorf("sequence = %d, want 1", n.Params.Sequence)
+	}
+}
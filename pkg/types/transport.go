@@ -0,0 +1,368 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Transport exchanges raw JSON-RPC messages with a peer. Implementations
+// decide the framing; callers see one complete message per Read.
+type Transport interface {
+	Read(ctx context.Context) (json.RawMessage, error)
+	Write(ctx context.Context, msg json.RawMessage) error
+}
+
+// StdioTransport exchanges newline-delimited messages over a byte stream, such
+// as a subprocess's stdin and stdout. It is safe for concurrent use. Blocking
+// reads and writes are not interrupted by ctx; it is only checked beforehand.
+type StdioTransport struct {
+	stream *streamTransport
+}
+
+func NewStdioTransport(r io.Reader, w io.Writer, opts ...FrameReaderOption) (*StdioTransport, error) {
+	stream, err := newStreamTransport(r, w, FramingNewline, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &StdioTransport{stream: stream}, nil
+}
+
+func (t *StdioTransport) Read(ctx context.Context) (json.RawMessage, error) {
+	return t.stream.read(ctx)
+}
+
+// Write compacts msg onto a single line and terminates it with a newline
+func (t *StdioTransport) Write(ctx context.Context, msg json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, msg); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	buf.WriteByte('\n')
+	return t.stream.write(ctx, buf.Bytes())
+}
+
+// HeaderFramedTransport exchanges LSP-style messages, each preceded by a
+// Content-Length header: "Content-Length: N\r\n\r\n<json>". It is safe for
+// concurrent use, with the same cancellation caveat as StdioTransport.
+type HeaderFramedTransport struct {
+	stream *streamTransport
+}
+
+func NewHeaderFramedTransport(r io.Reader, w io.Writer, opts ...FrameReaderOption) (*HeaderFramedTransport, error) {
+	stream, err := newStreamTransport(r, w, FramingContentLength, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &HeaderFramedTransport{stream: stream}, nil
+}
+
+// Read returns the body of the next frame after validating its headers
+func (t *HeaderFramedTransport) Read(ctx context.Context) (json.RawMessage, error) {
+	return t.stream.read(ctx)
+}
+
+// Write sends msg as a single frame with its exact byte length in the header
+func (t *HeaderFramedTransport) Write(ctx context.Context, msg json.RawMessage) error {
+	if !json.Valid(msg) {
+		return fmt.Errorf("invalid message: not valid JSON")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(msg))
+	buf.Write(msg)
+	return t.stream.write(ctx, buf.Bytes())
+}
+
+// streamTransport serializes reads and writes over a framed byte stream
+type streamTransport struct {
+	readMu  sync.Mutex
+	frames  *FrameReader
+	writeMu sync.Mutex
+	w       io.Writer
+}
+
+func newStreamTransport(r io.Reader, w io.Writer, framing Framing, opts []FrameReaderOption) (*streamTransport, error) {
+	if w == nil {
+		return nil, fmt.Errorf("writer cannot be nil")
+	}
+
+	// the transport's framing always wins over a WithFraming option
+	opts = append(append([]FrameReaderOption(nil), opts...), WithFraming(framing))
+	frames, err := NewFrameReader(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamTransport{frames: frames, w: w}, nil
+}
+
+func (s *streamTransport) read(ctx context.Context) (json.RawMessage, error) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.frames.Next()
+}
+
+func (s *streamTransport) write(ctx context.Context, frame []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(frame); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	return nil
+}
+
+// DefaultInitializeTimeout bounds how long PerformInitialize waits for the server
+const DefaultInitializeTimeout = 30 * time.Second
+
+// HandshakeOption configures PerformInitialize
+type HandshakeOption func(*handshakeConfig)
+
+type handshakeConfig struct {
+	timeout   time.Duration
+	id        RequestID
+	onMessage func(json.RawMessage)
+}
+
+// WithInitializeTimeout overrides DefaultInitializeTimeout. A zero or negative
+// timeout relies on the caller's context alone.
+func WithInitializeTimeout(d time.Duration) HandshakeOption {
+	return func(c *handshakeConfig) {
+		c.timeout = d
+	}
+}
+
+// WithInitializeRequestID sets the ID of the initialize request. It defaults to 0,
+// which never collides with IDs from an IDGenerator.
+func WithInitializeRequestID(id RequestID) HandshakeOption {
+	return func(c *handshakeConfig) {
+		c.id = id
+	}
+}
+
+// WithHandshakeMessageHandler receives the notifications and requests, other
+// than ping, that arrive while PerformInitialize waits for its response.
+// Without a handler notifications are discarded and requests are answered
+// with a method not found error.
+func WithHandshakeMessageHandler(handler func(msg json.RawMessage)) HandshakeOption {
+	return func(c *handshakeConfig) {
+		c.onMessage = handler
+	}
+}
+
+// PerformInitialize runs the client side of the handshake: it sends req, waits
+// for the matching response, checks the negotiated protocol version and sends
+// notifications/initialized. Pings from the server are answered while waiting.
+// An error response from the server is returned as *ErrorInfo.
+//
+// If ctx ends or the timeout expires first, a read may still be blocked in the
+// transport and would consume the next message, so the transport must be
+// closed rather than reused.
+func PerformInitialize(ctx context.Context, transport Transport, req *InitializeRequest, opts ...HandshakeOption) (*InitializeResult, error) {
+	if transport == nil {
+		return nil, fmt.Errorf("transport cannot be nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("initialize request cannot be nil")
+	}
+	if req.Method != MethodInitialize {
+		return nil, fmt.Errorf("unexpected method %q for initialize request", req.Method)
+	}
+
+	cfg := handshakeConfig{
+		timeout: DefaultInitializeTimeout,
+		id:      NewIntRequestID(0),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling initialize params: %w", err)
+	}
+	msg, err := json.Marshal(JSONRPCRequest{
+		JSONRPC: JSONRPCVersion,
+		ID:      cfg.id,
+		Method:  req.Method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling initialize request: %w", err)
+	}
+	if err := transport.Write(ctx, msg); err != nil {
+		return nil, fmt.Errorf("sending initialize request: %w", err)
+	}
+
+	resp, err := awaitResponse(ctx, transport, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var result InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("decoding initialize result: %w", err)
+	}
+	if !containsString(SupportedProtocolVersions, result.ProtocolVersion) {
+		return nil, fmt.Errorf("server negotiated unsupported protocol version %q", result.ProtocolVersion)
+	}
+
+	notification := NewInitializedNotification(nil)
+	msg, err = json.Marshal(JSONRPCNotification{
+		JSONRPC: JSONRPCVersion,
+		Method:  notification.Method,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling initialized notification: %w", err)
+	}
+	if err := transport.Write(ctx, msg); err != nil {
+		return nil, fmt.Errorf("sending initialized notification: %w", err)
+	}
+
+	return &result, nil
+}
+
+// awaitResponse reads until the response to cfg.id arrives, handling the
+// messages before it. Reads run in a separate goroutine so a transport that
+// ignores ctx cannot block past the deadline; that goroutine ends only when the
+// response arrives or the transport fails.
+func awaitResponse(ctx context.Context, transport Transport, cfg handshakeConfig) (*JSONRPCResponse, error) {
+	type readResult struct {
+		resp *JSONRPCResponse
+		err  error
+	}
+	done := make(chan readResult, 1)
+
+	go func() {
+		for {
+			data, err := transport.Read(ctx)
+			if err != nil {
+				done <- readResult{err: fmt.Errorf("reading initialize response: %w", err)}
+				return
+			}
+
+			var probe struct {
+				ID     *RequestID `json:"id"`
+				Method string     `json:"method"`
+			}
+			if err := json.Unmarshal(data, &probe); err != nil {
+				done <- readResult{err: fmt.Errorf("decoding message: %w", err)}
+				return
+			}
+			if probe.Method != "" {
+				handleHandshakeMessage(ctx, transport, cfg, probe.ID, probe.Method, data)
+				continue
+			}
+			if probe.ID == nil || *probe.ID != cfg.id {
+				continue
+			}
+
+			var resp JSONRPCResponse
+			if err := json.Unmarshal(data, &resp); err != nil {
+				done <- readResult{err: fmt.Errorf("decoding initialize response: %w", err)}
+				return
+			}
+			done <- readResult{resp: &resp}
+			return
+		}
+	}()
+
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for initialize response: %w", ctx.Err())
+	}
+}
+
+// handleHandshakeMessage answers pings and passes other requests and
+// notifications to the handshake's message handler
+func handleHandshakeMessage(ctx context.Context, transport Transport, cfg handshakeConfig, id *RequestID, method string, data json.RawMessage) {
+	if id != nil && method == MethodPing {
+		writeHandshakeResponse(ctx, transport, JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: *id, Result: json.RawMessage(`{}`)})
+		return
+	}
+	if cfg.onMessage != nil {
+		cfg.onMessage(data)
+		return
+	}
+	if id != nil {
+		writeHandshakeResponse(ctx, transport, JSONRPCResponse{
+			JSONRPC: JSONRPCVersion,
+			ID:      *id,
+			Error:   &ErrorInfo{Code: ErrMethodNotFound, Message: fmt.Sprintf("Method not found: %s", method)},
+		})
+	}
+}
+
+// writeHandshakeResponse sends resp on a best-effort basis: a failed write
+// surfaces as a failed read or write of the handshake itself
+func writeHandshakeResponse(ctx context.Context, transport Transport, resp JSONRPCResponse) {
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = transport.Write(ctx, msg)
+}
+
+/* Usage Example:
+func ExampleTransports() {
+    // Newline-delimited JSON over a subprocess's pipes
+    cmd := exec.Command("my-mcp-server")
+    stdin, _ := cmd.StdinPipe()
+    stdout, _ := cmd.StdoutPipe()
+    stdio, err := NewStdioTransport(stdout, stdin)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    // LSP-style Content-Length framing over a socket
+    conn, _ := net.Dial("tcp", "localhost:4000")
+    framed, err := NewHeaderFramedTransport(conn, conn, WithMaxFrameSize(1<<20))
+    if err != nil {
+        log.Fatal(err)
+    }
+}
+
+func ExamplePerformInitialize() {
+    clientInfo, _ := NewImplementation("example-client", "1.0.0")
+    request, err := NewInitializeRequest(*clientInfo)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    result, err := PerformInitialize(ctx, transport, request,
+        WithInitializeTimeout(5*time.Second),
+    )
+    if err != nil {
+        var rpcErr *ErrorInfo
+        if errors.As(err, &rpcErr) {
+            log.Fatalf("server rejected initialize: %v", rpcErr)
+        }
+        log.Fatal(err) // transport failure or timeout
+    }
+
+    fmt.Println(result.ServerInfo.Name, result.ProtocolVersion)
+}
+*/
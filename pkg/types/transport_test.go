@@ -0,0 +1,82 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPerformInitializeAnswersPings(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	defer serverIn.Close()
+	defer clientIn.Close()
+
+	clientTransport, err := NewStdioTransport(clientIn, clientOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTransport, err := NewStdioTransport(serverIn, serverOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var notified []json.RawMessage
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- func() error {
+			ctx := context.Background()
+			if _, err := serverTransport.Read(ctx); err != nil {
+				return err
+			}
+			for _, msg := range []string{
+				`{"jsonrpc":"2.0","method":"notifications/message","params":{"level":"info","data":"starting"}}`,
+				`{"jsonrpc":"2.0","id":"p1","method":"ping"}`,
+			} {
+				if err := serverTransport.Write(ctx, json.RawMessage(msg)); err != nil {
+					return err
+				}
+			}
+
+			pong, err := serverTransport.Read(ctx)
+			if err != nil {
+				return err
+			}
+			var resp JSONRPCResponse
+			if err := json.Unmarshal(pong, &resp); err != nil {
+				return err
+			}
+			if resp.ID != NewStringRequestID("p1") || resp.Error != nil {
+				t.Errorf("ping response = %s", pong)
+			}
+
+			result := `{"jsonrpc":"2.0","id":0,"result":{"protocolVersion":"` + LatestProtocolVersion + `","capabilities":{},"serverInfo":{"name":"s","version":"1"}}}`
+			if err := serverTransport.Write(ctx, json.RawMessage(result)); err != nil {
+				return err
+			}
+			_, err = serverTransport.Read(ctx) // notifications/initialized
+			return err
+		}()
+	}()
+
+	info, _ := NewImplementation("c", "1")
+	req, err := NewInitializeRequest(*info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = PerformInitialize(context.Background(), clientTransport, req,
+		WithInitializeTimeout(5*time.Second),
+		WithHandshakeMessageHandler(func(msg json.RawMessage) { notified = append(notified, msg) }),
+	)
+	if err != nil {
+		t.Fatalf("PerformInitialize: %v", err)
+	}
+	if err := <-serverErr; err != nil {
+		t.Fatalf("server: %v", err)
+	}
+	if len(notified) != 1 {
+		t.Errorf("handler received %d messages, want the log notification", len(notified))
+	}
+}